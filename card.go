@@ -0,0 +1,268 @@
+package wechat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/sohaha/zlsgo/zhttp"
+	"github.com/sohaha/zlsgo/zjson"
+	"github.com/sohaha/zlsgo/zstring"
+	"github.com/sohaha/zlsgo/ztype"
+)
+
+type (
+	// ChooseCardSign wx.chooseCard 参数
+	ChooseCardSign struct {
+		ShopID    string `json:"shopId"`
+		CardType  string `json:"cardType"`
+		CardID    string `json:"cardId"`
+		Timestamp int64  `json:"timestamp"`
+		NonceStr  string `json:"nonceStr"`
+		SignType  string `json:"signType"`
+		CardSign  string `json:"cardSign"`
+	}
+
+	// CardExt wx.addCard 的 cardExt 参数
+	CardExt struct {
+		Code                string `json:"code,omitempty"`
+		OpenID              string `json:"openid,omitempty"`
+		Timestamp           string `json:"timestamp"`
+		NonceStr            string `json:"nonce_str"`
+		FixedBeginTimestamp int64  `json:"fixed_begintimestamp,omitempty"`
+		OuterStr            string `json:"outer_str,omitempty"`
+		Signature           string `json:"signature"`
+	}
+
+	// AddCardItem wx.addCard 的 cardList 项
+	AddCardItem struct {
+		CardID  string `json:"cardId"`
+		CardExt string `json:"cardExt"`
+	}
+
+	// CardCode 卡券 Code 信息
+	CardCode struct {
+		Card struct {
+			CardID    string `json:"card_id"`
+			BeginTime int64  `json:"begin_time"`
+			EndTime   int64  `json:"end_time"`
+		} `json:"card"`
+		OpenID         string `json:"openid"`
+		CanConsume     bool   `json:"can_consume"`
+		UserCardStatus string `json:"user_card_status"`
+	}
+
+	// MemberCardUpdate 会员卡积分余额更新结果
+	MemberCardUpdate struct {
+		ResultBonus   int    `json:"result_bonus"`
+		ResultBalance int    `json:"result_balance"`
+		OpenID        string `json:"openid"`
+	}
+
+	// CardEvent 卡券事件
+	CardEvent struct {
+		CardID              string `xml:"CardId"`
+		UserCardCode        string
+		OldUserCardCode     string
+		IsGiveByFriend      int
+		FriendUserName      string
+		OuterID             int `xml:"OuterId"`
+		OuterStr            string
+		IsRestoreMemberCard int
+		UnionID             string `xml:"UnionId"`
+		IsChatRoom          int
+		ConsumeSource       string
+		LocationName        string
+		StaffOpenID         string `xml:"StaffOpenId"`
+		VerifyCode          string
+		RemarkAmount        string
+	}
+)
+
+const (
+	EventUserGetCard     = "user_get_card"
+	EventUserDelCard     = "user_del_card"
+	EventUserConsumeCard = "user_consume_card"
+)
+
+// SetCardTicket 设置卡券 api_ticket
+func (e *Engine) SetCardTicket(ticket string, expiresIn uint) error {
+	e.cache.Set(cacheCardTicket, ticket, expiresIn-60)
+	return nil
+}
+
+// GetCardTicket 获取卡券 api_ticket
+func (e *Engine) GetCardTicket() (string, error) {
+	data, err := e.cache.MustGet(cacheCardTicket, func(set func(data interface{}, lifeSpan time.Duration, interval ...bool)) (err error) {
+		var json *zjson.Res
+		json, err = e.HttpAccessTokenGet(e.apiURL+"/cgi-bin/ticket/getticket", zhttp.QueryParam{"type": "wx_card"})
+		if err != nil {
+			return
+		}
+		ticket := json.Get("ticket").String()
+		if ticket == "" {
+			return errors.New("ticket parsing failed")
+		}
+		set(ticket, time.Duration(json.Get("expires_in").Int()-200)*time.Second)
+		return
+	})
+	if err != nil {
+		return "", err
+	}
+	return data.(string), nil
+}
+
+// GetChooseCardSign 生成 wx.chooseCard 签名，cardType 与 cardID 可为空
+func (e *Engine) GetChooseCardSign(cardType, cardID, shopID string) (ChooseCardSign, error) {
+	ticket, err := e.GetCardTicket()
+	if err != nil {
+		return ChooseCardSign{}, err
+	}
+	timestamp := time.Now().Unix()
+	noncestr := zstring.Rand(16)
+	return ChooseCardSign{
+		ShopID:    shopID,
+		CardType:  cardType,
+		CardID:    cardID,
+		Timestamp: timestamp,
+		NonceStr:  noncestr,
+		SignType:  "SHA1",
+		CardSign: sha1Signature(ticket, e.GetAppID(), shopID, strconv.FormatInt(timestamp, 10),
+			noncestr, cardID, cardType),
+	}, nil
+}
+
+// GetAddCardItem 生成 wx.addCard 参数，ext 中的时间戳、随机串与签名会自动填充
+func (e *Engine) GetAddCardItem(cardID string, ext CardExt) (AddCardItem, error) {
+	ticket, err := e.GetCardTicket()
+	if err != nil {
+		return AddCardItem{}, err
+	}
+	ext.Timestamp = strconv.FormatInt(time.Now().Unix(), 10)
+	ext.NonceStr = zstring.Rand(16)
+	ext.Signature = sha1Signature(ticket, ext.Timestamp, cardID, ext.Code, ext.OpenID, ext.NonceStr)
+	b, err := json.Marshal(ext)
+	if err != nil {
+		return AddCardItem{}, err
+	}
+	return AddCardItem{CardID: cardID, CardExt: zstring.Bytes2String(b)}, nil
+}
+
+// CreateCard 创建卡券，返回 card_id
+func (e *Engine) CreateCard(card map[string]interface{}) (string, error) {
+	res, err := e.HttpAccessTokenPost(e.apiURL+"/card/create", map[string]interface{}{"card": card})
+	if err != nil {
+		return "", err
+	}
+	return res.Get("card_id").String(), nil
+}
+
+// GetCard 查看卡券详情
+func (e *Engine) GetCard(cardID string) (card ztype.Map, err error) {
+	var res *zjson.Res
+	res, err = e.HttpAccessTokenPost(e.apiURL+"/card/get", map[string]string{"card_id": cardID})
+	if err != nil {
+		return
+	}
+	var data struct {
+		Card ztype.Map `json:"card"`
+	}
+	err = unmarshalRes(res, &data)
+	return data.Card, err
+}
+
+// UpdateCard 更新卡券信息，cardType 为卡券类型的小写形式，如 member_card，返回是否需要重新审核
+func (e *Engine) UpdateCard(cardID, cardType string, data map[string]interface{}) (bool, error) {
+	res, err := e.HttpAccessTokenPost(e.apiURL+"/card/update", map[string]interface{}{
+		"card_id": cardID,
+		cardType:  data,
+	})
+	if err != nil {
+		return false, err
+	}
+	return res.Get("send_check").Bool(), nil
+}
+
+// DeleteCard 删除卡券
+func (e *Engine) DeleteCard(cardID string) error {
+	_, err := e.HttpAccessTokenPost(e.apiURL+"/card/delete", map[string]string{"card_id": cardID})
+	return err
+}
+
+// ConsumeCardCode 核销 Code，自定义 Code 卡券需要传入 cardID，返回用户 openid
+func (e *Engine) ConsumeCardCode(code string, cardID ...string) (openid string, err error) {
+	data := map[string]string{"code": code}
+	if len(cardID) > 0 && cardID[0] != "" {
+		data["card_id"] = cardID[0]
+	}
+	var res *zjson.Res
+	res, err = e.HttpAccessTokenPost(e.apiURL+"/card/code/consume", data)
+	if err != nil {
+		return
+	}
+	return res.Get("openid").String(), nil
+}
+
+// DecryptCardCode 解码 encrypt_code
+func (e *Engine) DecryptCardCode(encryptCode string) (string, error) {
+	res, err := e.HttpAccessTokenPost(e.apiURL+"/card/code/decrypt", map[string]string{"encrypt_code": encryptCode})
+	if err != nil {
+		return "", err
+	}
+	return res.Get("code").String(), nil
+}
+
+// GetCardCode 查询 Code
+func (e *Engine) GetCardCode(code, cardID string, checkConsume bool) (info CardCode, err error) {
+	var res *zjson.Res
+	res, err = e.HttpAccessTokenPost(e.apiURL+"/card/code/get", map[string]interface{}{
+		"code":          code,
+		"card_id":       cardID,
+		"check_consume": checkConsume,
+	})
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &info)
+	return
+}
+
+// ActivateMemberCard 激活会员卡
+func (e *Engine) ActivateMemberCard(cardID, code string, data map[string]interface{}) error {
+	if data == nil {
+		data = make(map[string]interface{}, 2)
+	}
+	data["card_id"] = cardID
+	data["code"] = code
+	_, err := e.HttpAccessTokenPost(e.apiURL+"/card/membercard/activate", data)
+	return err
+}
+
+// UpdateMemberCardUser 更新会员卡积分、余额等信息
+func (e *Engine) UpdateMemberCardUser(cardID, code string, data map[string]interface{}) (result MemberCardUpdate, err error) {
+	if data == nil {
+		data = make(map[string]interface{}, 2)
+	}
+	data["card_id"] = cardID
+	data["code"] = code
+	var res *zjson.Res
+	res, err = e.HttpAccessTokenPost(e.apiURL+"/card/membercard/updateuser", data)
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &result)
+	return
+}
+
+// CardEvent 解析卡券领取、删除、核销事件
+func (t *ReplySt) CardEvent() (event CardEvent, err error) {
+	switch t.Event {
+	case EventUserGetCard, EventUserDelCard, EventUserConsumeCard:
+		err = t.Unmarshal(&event)
+	default:
+		err = fmt.Errorf("not a card event: %s", t.Event)
+	}
+	return
+}
@@ -0,0 +1,112 @@
+package wechat
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestCardSignature(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	// api_ticket、timestamp、card_id、nonce_str 的值按字典序排序后拼接再 sha1
+	// 1404896688jonyqinojZ8YtyVyr30HheH3CM73y7h4jJEpjZ8Yt1XGILfi-FUsewpnnolGgZk
+	sign := "b2bf675f1383b47ba888a95901766be98f9f3e67"
+	tt.Equal(sign, sha1Signature("ojZ8YtyVyr30HheH3CM73y7h4jJE", "1404896688", "pjZ8Yt1XGILfi-FUsewpnnolGgZk", "jonyqin"))
+	tt.Equal(sign, sha1Signature("jonyqin", "pjZ8Yt1XGILfi-FUsewpnnolGgZk", "1404896688", "ojZ8YtyVyr30HheH3CM73y7h4jJE"))
+	// 空值不影响签名
+	tt.Equal(sign, sha1Signature("ojZ8YtyVyr30HheH3CM73y7h4jJE", "1404896688", "pjZ8Yt1XGILfi-FUsewpnnolGgZk", "", "", "jonyqin"))
+}
+
+func TestCardTicket(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	wx := New(&Mp{AppID: "wx_card_ticket"})
+	api := newMockAPI(wx, func(r mockRequest) string {
+		return `{"errcode":0,"errmsg":"ok","ticket":"card_ticket","expires_in":7200}`
+	})
+	defer api.Close()
+
+	ticket, err := wx.GetCardTicket()
+	tt.Equal(true, err == nil)
+	tt.Equal("card_ticket", ticket)
+	req := api.Last()
+	tt.Equal("/cgi-bin/ticket/getticket", req.Path)
+	tt.Equal("wx_card", req.Query.Get("type"))
+	tt.Equal("ACCESS_TOKEN", req.Query.Get("access_token"))
+
+	sign, err := wx.GetChooseCardSign("GROUPON", "card_id", "shop_id")
+	tt.Equal(true, err == nil)
+	tt.Equal("SHA1", sign.SignType)
+	tt.Equal(sha1Signature("card_ticket", "wx_card_ticket", "shop_id", strconv.FormatInt(sign.Timestamp, 10),
+		sign.NonceStr, "card_id", "GROUPON"), sign.CardSign)
+
+	item, err := wx.GetAddCardItem("card_id", CardExt{Code: "code", OpenID: "openid"})
+	tt.Equal(true, err == nil)
+	tt.Equal("card_id", item.CardID)
+	var ext CardExt
+	tt.Equal(true, json.Unmarshal([]byte(item.CardExt), &ext) == nil)
+	tt.Equal("code", ext.Code)
+	tt.Equal(sha1Signature("card_ticket", ext.Timestamp, "card_id", "code", "openid", ext.NonceStr), ext.Signature)
+
+	// 卡券 ticket 已缓存，且与 jsapi_ticket 分开存储
+	tt.Equal(1, len(api.Requests()))
+	_ = wx.SetCardTicket("new_ticket", 7200)
+	ticket, _ = wx.GetCardTicket()
+	tt.Equal("new_ticket", ticket)
+	_, err = wx.cache.Get(cacheJsapiTicket)
+	tt.Equal(true, err != nil)
+}
+
+func TestCardRequest(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	wx := New(&Mp{AppID: "wx_card_request"})
+	api := newMockAPI(wx, func(r mockRequest) string {
+		switch r.Path {
+		case "/card/create":
+			return `{"errcode":0,"errmsg":"ok","card_id":"p1Pj9jr90_SQRaVqYI239Ka1erkI"}`
+		case "/card/code/get":
+			return `{"errcode":0,"errmsg":"ok","card":{"card_id":"pbLatjk4T4Hx-QFQGL4zGQy27_Qg","begin_time":1457452800,"end_time":1463155199},"openid":"o1Pj9jmZvwSyyyyyyBa4aULW2mA","can_consume":true,"user_card_status":"NORMAL"}`
+		case "/card/membercard/updateuser":
+			return `{"errcode":0,"errmsg":"ok","result_bonus":100,"result_balance":200,"openid":"oFS7Fjl0WsZ9AMZqrI80nbIq8xrA"}`
+		case "/card/code/consume":
+			return `{"errcode":0,"errmsg":"ok","card":{"card_id":"pFS7Fjg8kV1IdDz01r4SQwMkuCKc"},"openid":"oFS7Fjl0WsZ9AMZqrI80nbIq8xrA"}`
+		}
+		return ""
+	})
+	defer api.Close()
+
+	cardID, err := wx.CreateCard(map[string]interface{}{"card_type": "GROUPON"})
+	tt.Equal(true, err == nil)
+	tt.Equal("p1Pj9jr90_SQRaVqYI239Ka1erkI", cardID)
+	tt.Equal("GROUPON", api.Last().JSON("card.card_type").String())
+
+	info, err := wx.GetCardCode("110201201245", "pbLatjk4T4Hx-QFQGL4zGQy27_Qg", true)
+	tt.Equal(true, err == nil)
+	tt.Equal(true, info.CanConsume)
+	tt.Equal("NORMAL", info.UserCardStatus)
+	tt.Equal(int64(1463155199), info.Card.EndTime)
+	req := api.Last()
+	tt.Equal("110201201245", req.JSON("code").String())
+	tt.Equal(true, req.JSON("check_consume").Bool())
+
+	openid, err := wx.ConsumeCardCode("12312313")
+	tt.Equal(true, err == nil)
+	tt.Equal("oFS7Fjl0WsZ9AMZqrI80nbIq8xrA", openid)
+	tt.Equal(false, api.Last().JSON("card_id").Exists())
+	_, _ = wx.ConsumeCardCode("12312313", "card_id")
+	tt.Equal("card_id", api.Last().JSON("card_id").String())
+
+	err = wx.ActivateMemberCard("card_id", "code", map[string]interface{}{"membership_number": "357898858"})
+	tt.Equal(true, err == nil)
+	req = api.Last()
+	tt.Equal("/card/membercard/activate", req.Path)
+	tt.Equal("card_id", req.JSON("card_id").String())
+	tt.Equal("code", req.JSON("code").String())
+	tt.Equal("357898858", req.JSON("membership_number").String())
+
+	result, err := wx.UpdateMemberCardUser("card_id", "code", map[string]interface{}{"add_bonus": 100})
+	tt.Equal(true, err == nil)
+	tt.Equal(MemberCardUpdate{ResultBonus: 100, ResultBalance: 200, OpenID: "oFS7Fjl0WsZ9AMZqrI80nbIq8xrA"}, result)
+	tt.Equal(100, api.Last().JSON("add_bonus").Int())
+}
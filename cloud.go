@@ -0,0 +1,253 @@
+package wechat
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+
+	"github.com/sohaha/zlsgo/zhttp"
+	"github.com/sohaha/zlsgo/zjson"
+	"github.com/sohaha/zlsgo/zstring"
+)
+
+type (
+	// CloudOptions 云开发选项
+	CloudOptions struct {
+		// Env 云开发环境 ID，默认使用 Weapp.CloudEnv
+		Env string
+	}
+
+	CloudOption func(*CloudOptions)
+
+	// CloudQueryResult 数据库查询结果，Data 中每项为记录的 JSON 字符串
+	CloudQueryResult struct {
+		Pager struct {
+			Offset int `json:"Offset"`
+			Limit  int `json:"Limit"`
+			Total  int `json:"Total"`
+		} `json:"pager"`
+		Data []string `json:"data"`
+	}
+
+	// CloudUpdateResult 数据库更新结果
+	CloudUpdateResult struct {
+		Matched  int    `json:"matched"`
+		Modified int    `json:"modified"`
+		ID       string `json:"id"`
+	}
+
+	// CloudUploadInfo 文件上传链接
+	CloudUploadInfo struct {
+		URL           string `json:"url"`
+		Token         string `json:"token"`
+		Authorization string `json:"authorization"`
+		FileID        string `json:"file_id"`
+		CosFileID     string `json:"cos_file_id"`
+	}
+
+	// CloudDownloadFile 文件下载链接
+	CloudDownloadFile struct {
+		FileID      string `json:"fileid"`
+		DownloadURL string `json:"download_url"`
+		Status      int    `json:"status"`
+		ErrMsg      string `json:"errmsg"`
+	}
+)
+
+const (
+	cloudErrCodeMin      = -599999
+	cloudErrCodeMax      = -500000
+	cloudDownloadMaxFile = 50
+)
+
+var (
+	ErrCloudEnv          = errors.New("cloud env is required")
+	ErrCloudDownloadFile = errors.New("the number of cloud files must be between 1 and 50")
+)
+
+// WithCloudEnv 指定云开发环境 ID
+func WithCloudEnv(env string) CloudOption {
+	return func(o *CloudOptions) {
+		o.Env = env
+	}
+}
+
+// IsCloudError 是否为云开发错误
+func IsCloudError(err error) bool {
+	code := ErrorCode(err)
+	return code >= cloudErrCodeMin && code <= cloudErrCodeMax
+}
+
+func (m *Weapp) cloudEnv(opts []CloudOption) (string, error) {
+	o := CloudOptions{Env: m.CloudEnv}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Env == "" {
+		return "", ErrCloudEnv
+	}
+	return o.Env, nil
+}
+
+func (m *Weapp) cloudPost(path string, data map[string]interface{}, opts []CloudOption) (*zjson.Res, error) {
+	env, err := m.cloudEnv(opts)
+	if err != nil {
+		return nil, err
+	}
+	data["env"] = env
+	return m.post("/tcb/"+path, data)
+}
+
+// InvokeCloudFunction 触发云函数，data 为云函数的传入参数，返回云函数的返回值
+func (m *Weapp) InvokeCloudFunction(name string, data interface{}, opts ...CloudOption) (string, error) {
+	env, err := m.cloudEnv(opts)
+	if err != nil {
+		return "", err
+	}
+	body := "{}"
+	if data != nil {
+		b, err := json.Marshal(data)
+		if err != nil {
+			return "", err
+		}
+		body = zstring.Bytes2String(b)
+	}
+	res, err := m.post("/tcb/invokecloudfunction", body,
+		zhttp.Header{"Content-Type": "application/json"}, zhttp.QueryParam{"env": env, "name": name})
+	if err != nil {
+		return "", err
+	}
+	return res.Get("resp_data").String(), nil
+}
+
+// DatabaseQuery 数据库查询记录，query 为数据库操作语句，如 db.collection("todos").limit(10).get()
+func (m *Weapp) DatabaseQuery(query string, opts ...CloudOption) (result CloudQueryResult, err error) {
+	var res *zjson.Res
+	res, err = m.cloudPost("databasequery", map[string]interface{}{"query": query}, opts)
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &result)
+	return
+}
+
+// DatabaseAdd 数据库插入记录，返回插入成功的记录 id
+func (m *Weapp) DatabaseAdd(query string, opts ...CloudOption) (ids []string, err error) {
+	var res *zjson.Res
+	res, err = m.cloudPost("databaseadd", map[string]interface{}{"query": query}, opts)
+	if err != nil {
+		return
+	}
+	var data struct {
+		IDList []string `json:"id_list"`
+	}
+	err = unmarshalRes(res, &data)
+	return data.IDList, err
+}
+
+// DatabaseUpdate 数据库更新记录
+func (m *Weapp) DatabaseUpdate(query string, opts ...CloudOption) (result CloudUpdateResult, err error) {
+	var res *zjson.Res
+	res, err = m.cloudPost("databaseupdate", map[string]interface{}{"query": query}, opts)
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &result)
+	return
+}
+
+// DatabaseDelete 数据库删除记录，返回删除的记录数量
+func (m *Weapp) DatabaseDelete(query string, opts ...CloudOption) (int, error) {
+	res, err := m.cloudPost("databasedelete", map[string]interface{}{"query": query}, opts)
+	if err != nil {
+		return 0, err
+	}
+	return res.Get("deleted").Int(), nil
+}
+
+// DatabaseAggregate 数据库聚合，Data 中每项为记录的 JSON 字符串
+func (m *Weapp) DatabaseAggregate(query string, opts ...CloudOption) (data []string, err error) {
+	var res *zjson.Res
+	res, err = m.cloudPost("databaseaggregate", map[string]interface{}{"query": query}, opts)
+	if err != nil {
+		return
+	}
+	var result struct {
+		Data []string `json:"data"`
+	}
+	err = unmarshalRes(res, &result)
+	return result.Data, err
+}
+
+// DatabaseCount 统计集合记录数或统计查询语句对应的结果记录数
+func (m *Weapp) DatabaseCount(query string, opts ...CloudOption) (int, error) {
+	res, err := m.cloudPost("databasecount", map[string]interface{}{"query": query}, opts)
+	if err != nil {
+		return 0, err
+	}
+	return res.Get("count").Int(), nil
+}
+
+// GetCloudUploadInfo 获取文件上传链接，path 为云存储中的文件路径
+func (m *Weapp) GetCloudUploadInfo(path string, opts ...CloudOption) (info CloudUploadInfo, err error) {
+	var res *zjson.Res
+	res, err = m.cloudPost("uploadfile", map[string]interface{}{"path": path}, opts)
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &info)
+	return
+}
+
+// UploadCloudFile 上传文件到云存储，返回文件 ID
+func (m *Weapp) UploadCloudFile(path string, r io.Reader, opts ...CloudOption) (string, error) {
+	info, err := m.GetCloudUploadInfo(path, opts...)
+	if err != nil {
+		return "", err
+	}
+	file, ok := r.(io.ReadCloser)
+	if !ok {
+		file = ioutil.NopCloser(r)
+	}
+	res, err := http.Post(info.URL, zhttp.Param{
+		"key":                  path,
+		"Signature":            info.Authorization,
+		"x-cos-security-token": info.Token,
+		"x-cos-meta-fileid":    info.CosFileID,
+	}, zhttp.UploadFile{
+		FieldName: "file",
+		FileName:  filepath.Base(path),
+		File:      file,
+	})
+	if err != nil {
+		return "", httpError{Code: -2, Msg: "网络请求失败: " + err.Error(), Err: err}
+	}
+	if code := res.StatusCode(); code != 200 && code != 204 {
+		return "", httpError{Code: -2, Msg: "文件上传失败: " + strconv.Itoa(code)}
+	}
+	return info.FileID, nil
+}
+
+// BatchDownloadCloudFile 获取文件下载链接，maxAge 为链接有效期秒数，单次最多 50 个文件
+func (m *Weapp) BatchDownloadCloudFile(fileIDs []string, maxAge int, opts ...CloudOption) (list []CloudDownloadFile, err error) {
+	if len(fileIDs) == 0 || len(fileIDs) > cloudDownloadMaxFile {
+		return nil, ErrCloudDownloadFile
+	}
+	files := make([]map[string]interface{}, 0, len(fileIDs))
+	for _, id := range fileIDs {
+		files = append(files, map[string]interface{}{"fileid": id, "max_age": maxAge})
+	}
+	var res *zjson.Res
+	res, err = m.cloudPost("batchdownloadfile", map[string]interface{}{"file_list": files}, opts)
+	if err != nil {
+		return
+	}
+	var data struct {
+		FileList []CloudDownloadFile `json:"file_list"`
+	}
+	err = unmarshalRes(res, &data)
+	return data.FileList, err
+}
@@ -0,0 +1,121 @@
+package wechat
+
+import (
+	"bytes"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestCloudEnv(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_cloud"}
+	New(weapp)
+
+	_, err := weapp.DatabaseCount(`db.collection("todos").count()`)
+	tt.Equal(ErrCloudEnv, err)
+
+	weapp.CloudEnv = "prod-1"
+	env, err := weapp.cloudEnv(nil)
+	tt.Equal(true, err == nil)
+	tt.Equal("prod-1", env)
+	env, _ = weapp.cloudEnv([]CloudOption{WithCloudEnv("test-1")})
+	tt.Equal("test-1", env)
+
+	_, err = weapp.BatchDownloadCloudFile(nil, 3600)
+	tt.Equal(ErrCloudDownloadFile, err)
+
+	tt.Equal(true, IsCloudError(codeError(-502005)))
+	tt.Equal("数据库集合不存在", ErrorMsg(codeError(-502005)))
+	tt.Equal(false, IsCloudError(codeError(40001)))
+}
+
+func TestCloudRequest(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_cloud_request", CloudEnv: "prod-1"}
+	e := New(weapp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		switch r.Path {
+		case "/tcb/invokecloudfunction":
+			return `{"errcode":0,"errmsg":"ok","resp_data":"{\"sum\":3}"}`
+		case "/tcb/databasecount":
+			return `{"errcode":0,"errmsg":"ok","count":2}`
+		}
+		return `{"errcode":-501000,"errmsg":"fail"}`
+	})
+	defer api.Close()
+
+	resp, err := weapp.InvokeCloudFunction("add", map[string]int{"a": 1, "b": 2}, WithCloudEnv("test-1"))
+	tt.Equal(true, err == nil)
+	tt.Equal(`{"sum":3}`, resp)
+	req := api.Last()
+	tt.Equal("add", req.Query.Get("name"))
+	tt.Equal("test-1", req.Query.Get("env"))
+	tt.Equal("ACCESS_TOKEN", req.Query.Get("access_token"))
+	tt.Equal(2, req.JSON("b").Int())
+	tt.Equal(false, req.JSON("env").Exists())
+
+	count, err := weapp.DatabaseCount(`db.collection("todos").count()`)
+	tt.Equal(true, err == nil)
+	tt.Equal(2, count)
+	req = api.Last()
+	tt.Equal("prod-1", req.JSON("env").String())
+	tt.Equal(`db.collection("todos").count()`, req.JSON("query").String())
+
+	_, err = weapp.DatabaseDelete(`db.collection("todos").remove()`)
+	tt.Equal(true, IsCloudError(err))
+}
+
+func TestUploadCloudFile(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_cloud_upload", CloudEnv: "prod-1"}
+	e := New(weapp)
+	var (
+		uploadURL string
+		form      = map[string]string{}
+	)
+	api := newMockAPI(e, func(r mockRequest) string {
+		if r.Path == "/cos" {
+			_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			mr := multipart.NewReader(bytes.NewReader(r.Body), params["boundary"])
+			for {
+				part, err := mr.NextPart()
+				if err != nil {
+					break
+				}
+				b, _ := ioutil.ReadAll(part)
+				form[part.FormName()] = string(b)
+				if part.FileName() != "" {
+					form["filename"] = part.FileName()
+				}
+			}
+			return ""
+		}
+		return `{"errcode":0,"errmsg":"ok","url":"` + uploadURL + `","token":"token","authorization":"sign","file_id":"cloud://prod-1/a/b.txt","cos_file_id":"cos_id"}`
+	})
+	defer api.Close()
+
+	uploadURL = api.URL + "/cos"
+	id, err := weapp.UploadCloudFile("a/b.txt", strings.NewReader("hello"))
+	tt.Equal(true, err == nil)
+	tt.Equal("cloud://prod-1/a/b.txt", id)
+	tt.Equal("prod-1", api.Requests()[0].JSON("env").String())
+	tt.Equal("a/b.txt", api.Requests()[0].JSON("path").String())
+	tt.Equal("a/b.txt", form["key"])
+	tt.Equal("sign", form["Signature"])
+	tt.Equal("token", form["x-cos-security-token"])
+	tt.Equal("cos_id", form["x-cos-meta-fileid"])
+	tt.Equal("hello", form["file"])
+	tt.Equal("b.txt", form["filename"])
+
+	// 上传失败时保留原始错误
+	uploadURL = "http://127.0.0.1:0/cos"
+	_, err = weapp.UploadCloudFile("a/b.txt", strings.NewReader("hello"))
+	tt.Equal(-2, ErrorCode(err))
+	httpErr, _ := err.(httpError)
+	tt.Equal(true, httpErr.Err != nil)
+}
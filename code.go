@@ -7,6 +7,21 @@ import (
 
 var errNoJSON = errors.New("no json")
 var errCode = map[int]string{
+	-501000: "云开发系统错误",
+	-501001: "云开发资源不存在或不可用",
+	-501002: "云开发服务响应超时",
+	-501003: "云开发调用频率超过限制",
+	-501004: "云开发资源超过限制",
+	-501005: "无权限访问云开发资源",
+	-501006: "云开发参数错误",
+	-501007: "云开发参数不合法",
+	-502001: "数据库请求失败",
+	-502002: "非法的数据库指令",
+	-502003: "无权限操作数据库",
+	-502005: "数据库集合不存在",
+	-503001: "云存储请求失败",
+	-504001: "云函数调用失败",
+	-504002: "云函数执行失败",
 	-1:      "系统繁忙",
 	0:       "请求成功",
 	40001:   "AppSecret 错误，或者 access_token 无效",
@@ -49,7 +64,10 @@ var errCode = map[int]string{
 	40051:   "分组名字不合法",
 	40066:   "不合法的 url",
 	40099:   "该 code 已被核销",
+	40165:   "不合法的小程序页面路径",
+	40212:   "不合法的 query",
 	40226:   "高风险等级用户，登录拦截",
+	44990:   "接口请求太快（超过 100 次/秒）",
 	41001:   "缺少 access_token 参数",
 	40125:   "appsecret 无效",
 	45011:   "频率限制，每个用户每分钟100次",
@@ -71,6 +89,8 @@ var errCode = map[int]string{
 	43003:   "需要 HTTPS 请求",
 	43004:   "需要接收者关注",
 	43005:   "需要好友关系",
+	43101:   "用户拒绝接受消息，或订阅次数已用完",
+	47003:   "模板参数不准确",
 	44001:   "多媒体文件为空",
 	44002:   "POST 的数据包为空",
 	44003:   "图文消息内容为空",
@@ -97,6 +117,9 @@ var errCode = map[int]string{
 	45057:   "该标签下粉丝数超过10w，不允许直接删除",
 	45059:   "有粉丝身上的标签数已经超过限制",
 	45159:   "非法的 tag_id",
+	45065:   "相同 clientmsgid 已存在群发记录",
+	45066:   "相同 clientmsgid 重试速度过快，请间隔1分钟重试",
+	45067:   "clientmsgid 长度超过限制",
 	46001:   "不存在媒体数据",
 	46002:   "不存在的菜单版本",
 	46003:   "不存在的菜单数据",
@@ -124,6 +147,11 @@ var errCode = map[int]string{
 	61459:   "客服不在线",
 	61500:   "日期格式错误",
 	61501:   "日期范围错误",
+	85079:   "小程序没有线上版本",
+	85400:   "长期有效的 Scheme 或 URL Link 已达生成上限",
+	85401:   "有效时间必须在当前时间之后且不能超过 30 天",
+	85402:   "env_version 不合法",
+	87009:   "无效的签名",
 	7000000: "请求正常，无语义结果",
 	7000001: "缺失请求参数",
 	7000002: "signature 参数无效",
@@ -150,15 +178,26 @@ func code2Str(code int) string {
 	return s
 }
 
+// codeError 使用错误码构造本地校验错误
+func codeError(code int) error {
+	return httpError{Code: code, Msg: code2Str(code)}
+}
+
 type httpError struct {
 	Code int
 	Msg  string
+	// Err 原始错误，如网络请求错误
+	Err error
 }
 
 func (e httpError) Error() string {
 	return e.Msg
 }
 
+func (e httpError) Unwrap() error {
+	return e.Err
+}
+
 func (e httpError) message() (msg string) {
 	msg = code2Str(e.Code)
 	if msg == "" {
@@ -0,0 +1,119 @@
+package wechat
+
+import (
+	"github.com/sohaha/zlsgo/zjson"
+)
+
+type (
+	// CommentType 评论类型
+	CommentType int
+
+	// Comment 图文评论
+	Comment struct {
+		UserCommentID int64  `json:"user_comment_id"`
+		OpenID        string `json:"openid"`
+		CreateTime    int64  `json:"create_time"`
+		Content       string `json:"content"`
+		CommentType   int    `json:"comment_type"`
+		Reply         *struct {
+			Content    string `json:"content"`
+			CreateTime int64  `json:"create_time"`
+		} `json:"reply,omitempty"`
+	}
+)
+
+const (
+	CommentAll CommentType = iota
+	CommentNormal
+	CommentElected
+)
+
+const commentMaxCount = 49
+
+func (m *Mp) commentPost(path string, msgDataID int64, index int, data map[string]interface{}) (*zjson.Res, error) {
+	if data == nil {
+		data = make(map[string]interface{}, 2)
+	}
+	data["msg_data_id"] = msgDataID
+	data["index"] = index
+	return m.post("/cgi-bin/comment/"+path, data)
+}
+
+// OpenComment 打开已群发文章评论
+func (m *Mp) OpenComment(msgDataID int64, index int) error {
+	_, err := m.commentPost("open", msgDataID, index, nil)
+	return err
+}
+
+// CloseComment 关闭已群发文章评论
+func (m *Mp) CloseComment(msgDataID int64, index int) error {
+	_, err := m.commentPost("close", msgDataID, index, nil)
+	return err
+}
+
+// ListComment 查看指定文章的评论数据，count 最大 49
+func (m *Mp) ListComment(msgDataID int64, index int, t CommentType, begin, count int) (total int, list []Comment, err error) {
+	if count < 1 || count > commentMaxCount {
+		count = commentMaxCount
+	}
+	var res *zjson.Res
+	res, err = m.commentPost("list", msgDataID, index, map[string]interface{}{
+		"begin": begin,
+		"count": count,
+		"type":  t,
+	})
+	if err != nil {
+		return
+	}
+	var data struct {
+		Total   int       `json:"total"`
+		Comment []Comment `json:"comment"`
+	}
+	err = unmarshalRes(res, &data)
+	return data.Total, data.Comment, err
+}
+
+// CommentForEach 自动翻页遍历文章评论，fn 返回 false 时停止
+func (m *Mp) CommentForEach(msgDataID int64, index int, t CommentType, fn func(comment Comment) bool) error {
+	return forEachOffset(func(begin int) (int, int, bool, error) {
+		total, list, err := m.ListComment(msgDataID, index, t, begin, commentMaxCount)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		stop := eachItem(len(list), func(i int) bool { return fn(list[i]) })
+		return len(list), total, stop, nil
+	})
+}
+
+// MarkElectComment 将评论标记精选
+func (m *Mp) MarkElectComment(msgDataID int64, index int, userCommentID int64) error {
+	_, err := m.commentPost("markelect", msgDataID, index, map[string]interface{}{"user_comment_id": userCommentID})
+	return err
+}
+
+// UnmarkElectComment 将评论取消精选
+func (m *Mp) UnmarkElectComment(msgDataID int64, index int, userCommentID int64) error {
+	_, err := m.commentPost("unmarkelect", msgDataID, index, map[string]interface{}{"user_comment_id": userCommentID})
+	return err
+}
+
+// DeleteComment 删除评论
+func (m *Mp) DeleteComment(msgDataID int64, index int, userCommentID int64) error {
+	_, err := m.commentPost("delete", msgDataID, index, map[string]interface{}{"user_comment_id": userCommentID})
+	return err
+}
+
+// ReplyComment 回复评论
+func (m *Mp) ReplyComment(msgDataID int64, index int, userCommentID int64, content string) error {
+	_, err := m.commentPost("reply/add", msgDataID, index, map[string]interface{}{
+		"user_comment_id": userCommentID,
+		"content":         content,
+	})
+	return err
+}
+
+// DeleteCommentReply 删除回复
+func (m *Mp) DeleteCommentReply(msgDataID int64, index int, userCommentID int64) error {
+	_, err := m.commentPost("reply/delete", msgDataID, index, map[string]interface{}{"user_comment_id": userCommentID})
+	return err
+}
@@ -0,0 +1,108 @@
+package wechat
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestListComment(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	mp := &Mp{AppID: "wx_comment_list"}
+	e := New(mp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		return `{"errcode":0,"errmsg":"ok","total":1,"comment":[{"user_comment_id":1,"openid":"openid","content":"hi","comment_type":1,"reply":{"content":"thanks","create_time":2}}]}`
+	})
+	defer api.Close()
+
+	for count, expected := range map[int]int{0: 49, 50: 49, 10: 10} {
+		total, list, err := mp.ListComment(100, 1, CommentElected, 5, count)
+		tt.Equal(true, err == nil)
+		tt.Equal(1, total)
+		tt.Equal("thanks", list[0].Reply.Content)
+		req := api.Last()
+		tt.Equal("/cgi-bin/comment/list", req.Path)
+		tt.Equal(expected, req.JSON("count").Int())
+		tt.Equal(5, req.JSON("begin").Int())
+		tt.Equal(2, req.JSON("type").Int())
+		tt.Equal(100, req.JSON("msg_data_id").Int())
+		tt.Equal(1, req.JSON("index").Int())
+	}
+}
+
+func TestCommentForEach(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	mp := &Mp{AppID: "wx_comment_each"}
+	e := New(mp)
+	total, empty := 0, false
+	api := newMockAPI(e, func(r mockRequest) string {
+		begin := r.JSON("begin").Int()
+		if empty || begin >= total {
+			return `{"errcode":0,"total":` + strconv.Itoa(total) + `,"comment":[]}`
+		}
+		list := ""
+		for i := begin; i < begin+r.JSON("count").Int() && i < total; i++ {
+			if list != "" {
+				list += ","
+			}
+			list += `{"user_comment_id":` + strconv.Itoa(i+1) + `}`
+		}
+		return `{"errcode":0,"total":` + strconv.Itoa(total) + `,"comment":[` + list + `]}`
+	})
+	defer api.Close()
+
+	each := func(stop int) (ids []int64) {
+		err := mp.CommentForEach(1, 0, CommentAll, func(comment Comment) bool {
+			ids = append(ids, comment.UserCommentID)
+			return len(ids) != stop
+		})
+		tt.Equal(true, err == nil)
+		return
+	}
+
+	total = 100
+	tt.Equal(100, len(each(0)))
+	tt.Equal(3, len(api.Requests()))
+
+	tt.Equal([]int64{1, 2, 3}, each(3))
+	tt.Equal(4, len(api.Requests()))
+
+	// total 与实际返回不一致时，空页停止
+	empty = true
+	tt.Equal(0, len(each(0)))
+	tt.Equal(5, len(api.Requests()))
+}
+
+func TestCommentRequest(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	mp := &Mp{AppID: "wx_comment_request"}
+	e := New(mp)
+	api := newMockAPI(e, nil)
+	defer api.Close()
+
+	for path, call := range map[string]func() error{
+		"/cgi-bin/comment/open":         func() error { return mp.OpenComment(100, 1) },
+		"/cgi-bin/comment/close":        func() error { return mp.CloseComment(100, 1) },
+		"/cgi-bin/comment/markelect":    func() error { return mp.MarkElectComment(100, 1, 7) },
+		"/cgi-bin/comment/unmarkelect":  func() error { return mp.UnmarkElectComment(100, 1, 7) },
+		"/cgi-bin/comment/delete":       func() error { return mp.DeleteComment(100, 1, 7) },
+		"/cgi-bin/comment/reply/add":    func() error { return mp.ReplyComment(100, 1, 7, "thanks") },
+		"/cgi-bin/comment/reply/delete": func() error { return mp.DeleteCommentReply(100, 1, 7) },
+	} {
+		tt.Equal(true, call() == nil)
+		req := api.Last()
+		tt.Equal(path, req.Path)
+		tt.Equal(100, req.JSON("msg_data_id").Int())
+		tt.Equal(1, req.JSON("index").Int())
+		switch path {
+		case "/cgi-bin/comment/open", "/cgi-bin/comment/close":
+			tt.Equal(false, req.JSON("user_comment_id").Exists())
+		case "/cgi-bin/comment/reply/add":
+			tt.Equal("thanks", req.JSON("content").String())
+			fallthrough
+		default:
+			tt.Equal(7, req.JSON("user_comment_id").Int())
+		}
+	}
+}
@@ -54,6 +54,9 @@ func aesDecrypt(data string, key string, iv ...string) ([]byte,
 	if err != nil {
 		return nil, err
 	}
+	if len(cipherData) == 0 || len(cipherData)%block.BlockSize() != 0 {
+		return nil, errors.New("ciphertext is not a multiple of the block size")
+	}
 	var ivRaw []byte
 	plainText := make([]byte, len(cipherData))
 	if len(iv) == 0 {
@@ -61,6 +64,9 @@ func aesDecrypt(data string, key string, iv ...string) ([]byte,
 	} else {
 		ivRaw = zstring.String2Bytes(iv[0])
 	}
+	if len(ivRaw) != aes.BlockSize {
+		return nil, errors.New("iv length must equal block size")
+	}
 	blockMode := cipher.NewCBCDecrypter(block, ivRaw)
 	blockMode.CryptBlocks(plainText, cipherData)
 
@@ -83,8 +89,11 @@ func PKCS7Padding(cipherText []byte, blockSize int) []byte {
 
 func PKCS7UnPadding(plainText []byte, blockSize int) []byte {
 	l := len(plainText)
+	if l == 0 {
+		return plainText
+	}
 	unpadding := int(plainText[l-1])
-	if unpadding < 0 || unpadding > blockSize {
+	if unpadding < 0 || unpadding > blockSize || unpadding > l {
 		unpadding = 0
 	}
 	return plainText[:(l - unpadding)]
@@ -97,7 +106,7 @@ func parsePlainText(plaintext []byte) ([]byte, uint32, []byte, []byte, error) {
 	}
 	random := plaintext[:16]
 	msgLen := binary.BigEndian.Uint32(plaintext[16:20])
-	if textLen < (20 + msgLen) {
+	if msgLen > textLen-20 {
 		return nil, 0, nil, nil, errors.New("plain is to small 2")
 	}
 	msg := plaintext[20 : 20+msgLen]
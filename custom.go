@@ -0,0 +1,134 @@
+package wechat
+
+type (
+	// CustomMessage 客服消息
+	CustomMessage interface {
+		customType() string
+	}
+
+	// CustomText 文本消息
+	CustomText struct {
+		Content string `json:"content"`
+	}
+
+	// CustomImage 图片消息
+	CustomImage struct {
+		MediaID string `json:"media_id"`
+	}
+
+	// CustomVoice 语音消息
+	CustomVoice struct {
+		MediaID string `json:"media_id"`
+	}
+
+	// CustomVideo 视频消息
+	CustomVideo struct {
+		MediaID      string `json:"media_id"`
+		ThumbMediaID string `json:"thumb_media_id"`
+		Title        string `json:"title,omitempty"`
+		Description  string `json:"description,omitempty"`
+	}
+
+	// CustomMusic 音乐消息
+	CustomMusic struct {
+		Title        string `json:"title,omitempty"`
+		Description  string `json:"description,omitempty"`
+		MusicURL     string `json:"musicurl"`
+		HQMusicURL   string `json:"hqmusicurl"`
+		ThumbMediaID string `json:"thumb_media_id"`
+	}
+
+	// CustomNewsArticle 外链图文
+	CustomNewsArticle struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		URL         string `json:"url"`
+		PicURL      string `json:"picurl"`
+	}
+
+	// CustomNews 外链图文消息，图文数量只能为 1
+	CustomNews struct {
+		Articles []CustomNewsArticle `json:"articles"`
+	}
+
+	// CustomMpNews 已发布的图文消息
+	CustomMpNews struct {
+		MediaID string `json:"media_id"`
+	}
+
+	// CustomMenuItem 菜单项
+	CustomMenuItem struct {
+		ID      string `json:"id"`
+		Content string `json:"content"`
+	}
+
+	// CustomMenu 菜单消息
+	CustomMenu struct {
+		HeadContent string           `json:"head_content"`
+		List        []CustomMenuItem `json:"list"`
+		TailContent string           `json:"tail_content"`
+	}
+
+	// CustomLink 图文链接消息，仅小程序客服消息支持
+	CustomLink struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		URL         string `json:"url"`
+		ThumbURL    string `json:"thumb_url"`
+	}
+
+	// CustomMiniProgramPage 小程序卡片消息
+	CustomMiniProgramPage struct {
+		Title        string `json:"title"`
+		AppID        string `json:"appid,omitempty"`
+		PagePath     string `json:"pagepath"`
+		ThumbMediaID string `json:"thumb_media_id"`
+	}
+)
+
+func (CustomText) customType() string            { return "text" }
+func (CustomImage) customType() string           { return "image" }
+func (CustomVoice) customType() string           { return "voice" }
+func (CustomVideo) customType() string           { return "video" }
+func (CustomMusic) customType() string           { return "music" }
+func (CustomNews) customType() string            { return "news" }
+func (CustomMpNews) customType() string          { return "mpnews" }
+func (CustomMenu) customType() string            { return "msgmenu" }
+func (CustomLink) customType() string            { return "link" }
+func (CustomMiniProgramPage) customType() string { return "miniprogrampage" }
+
+func customMessageData(openid string, msg CustomMessage, kfAccount string) map[string]interface{} {
+	msgType := msg.customType()
+	data := map[string]interface{}{
+		"touser":  openid,
+		"msgtype": msgType,
+		msgType:   msg,
+	}
+	if kfAccount != "" {
+		data["customservice"] = map[string]string{"kf_account": kfAccount}
+	}
+	return data
+}
+
+// SendCustomMessage 发送客服消息，可指定客服账号
+func (e *Engine) SendCustomMessage(openid string, msg CustomMessage, kfAccount ...string) error {
+	account := ""
+	if len(kfAccount) > 0 {
+		account = kfAccount[0]
+	}
+	_, err := e.HttpAccessTokenPost(e.apiURL+"/cgi-bin/message/custom/send", customMessageData(openid, msg, account))
+	return err
+}
+
+// SetTyping 下发客服输入状态
+func (e *Engine) SetTyping(openid string, typing bool) error {
+	command := "CancelTyping"
+	if typing {
+		command = "Typing"
+	}
+	_, err := e.HttpAccessTokenPost(e.apiURL+"/cgi-bin/message/custom/typing", map[string]string{
+		"touser":  openid,
+		"command": command,
+	})
+	return err
+}
@@ -0,0 +1,248 @@
+package wechat
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type (
+	// DatacubeUserSummary 用户增减数据
+	DatacubeUserSummary struct {
+		RefDate    string `json:"ref_date"`
+		UserSource int    `json:"user_source"`
+		NewUser    int    `json:"new_user"`
+		CancelUser int    `json:"cancel_user"`
+	}
+
+	// DatacubeUserCumulate 累计用户数据
+	DatacubeUserCumulate struct {
+		RefDate      string `json:"ref_date"`
+		CumulateUser int    `json:"cumulate_user"`
+	}
+
+	// DatacubeArticleSummary 图文群发每日数据
+	DatacubeArticleSummary struct {
+		RefDate          string `json:"ref_date"`
+		RefHour          int    `json:"ref_hour"`
+		MsgID            string `json:"msgid"`
+		Title            string `json:"title"`
+		IntPageReadUser  int    `json:"int_page_read_user"`
+		IntPageReadCount int    `json:"int_page_read_count"`
+		OriPageReadUser  int    `json:"ori_page_read_user"`
+		OriPageReadCount int    `json:"ori_page_read_count"`
+		ShareUser        int    `json:"share_user"`
+		ShareCount       int    `json:"share_count"`
+		AddToFavUser     int    `json:"add_to_fav_user"`
+		AddToFavCount    int    `json:"add_to_fav_count"`
+	}
+
+	// DatacubeArticleTotalDetail 图文群发总数据明细
+	DatacubeArticleTotalDetail struct {
+		StatDate                    string `json:"stat_date"`
+		TargetUser                  int    `json:"target_user"`
+		IntPageReadUser             int    `json:"int_page_read_user"`
+		IntPageReadCount            int    `json:"int_page_read_count"`
+		OriPageReadUser             int    `json:"ori_page_read_user"`
+		OriPageReadCount            int    `json:"ori_page_read_count"`
+		ShareUser                   int    `json:"share_user"`
+		ShareCount                  int    `json:"share_count"`
+		AddToFavUser                int    `json:"add_to_fav_user"`
+		AddToFavCount               int    `json:"add_to_fav_count"`
+		IntPageFromSessionReadUser  int    `json:"int_page_from_session_read_user"`
+		IntPageFromSessionReadCount int    `json:"int_page_from_session_read_count"`
+		IntPageFromHistMsgReadUser  int    `json:"int_page_from_hist_msg_read_user"`
+		IntPageFromHistMsgReadCount int    `json:"int_page_from_hist_msg_read_count"`
+		IntPageFromFeedReadUser     int    `json:"int_page_from_feed_read_user"`
+		IntPageFromFeedReadCount    int    `json:"int_page_from_feed_read_count"`
+		IntPageFromFriendsReadUser  int    `json:"int_page_from_friends_read_user"`
+		IntPageFromFriendsReadCount int    `json:"int_page_from_friends_read_count"`
+		IntPageFromOtherReadUser    int    `json:"int_page_from_other_read_user"`
+		IntPageFromOtherReadCount   int    `json:"int_page_from_other_read_count"`
+		FeedShareFromSessionUser    int    `json:"feed_share_from_session_user"`
+		FeedShareFromSessionCnt     int    `json:"feed_share_from_session_cnt"`
+		FeedShareFromFeedUser       int    `json:"feed_share_from_feed_user"`
+		FeedShareFromFeedCnt        int    `json:"feed_share_from_feed_cnt"`
+		FeedShareFromOtherUser      int    `json:"feed_share_from_other_user"`
+		FeedShareFromOtherCnt       int    `json:"feed_share_from_other_cnt"`
+	}
+
+	// DatacubeArticleTotal 图文群发总数据
+	DatacubeArticleTotal struct {
+		RefDate string                       `json:"ref_date"`
+		MsgID   string                       `json:"msgid"`
+		Title   string                       `json:"title"`
+		Details []DatacubeArticleTotalDetail `json:"details"`
+	}
+
+	// DatacubeUserRead 图文统计数据
+	DatacubeUserRead struct {
+		RefDate          string `json:"ref_date"`
+		RefHour          int    `json:"ref_hour"`
+		UserSource       int    `json:"user_source"`
+		IntPageReadUser  int    `json:"int_page_read_user"`
+		IntPageReadCount int    `json:"int_page_read_count"`
+		OriPageReadUser  int    `json:"ori_page_read_user"`
+		OriPageReadCount int    `json:"ori_page_read_count"`
+		ShareUser        int    `json:"share_user"`
+		ShareCount       int    `json:"share_count"`
+		AddToFavUser     int    `json:"add_to_fav_user"`
+		AddToFavCount    int    `json:"add_to_fav_count"`
+	}
+
+	// DatacubeUserShare 图文分享转发数据
+	DatacubeUserShare struct {
+		RefDate    string `json:"ref_date"`
+		RefHour    int    `json:"ref_hour"`
+		ShareScene int    `json:"share_scene"`
+		ShareCount int    `json:"share_count"`
+		ShareUser  int    `json:"share_user"`
+	}
+
+	// DatacubeUpstreamMsg 消息发送概况数据
+	DatacubeUpstreamMsg struct {
+		RefDate  string `json:"ref_date"`
+		RefHour  int    `json:"ref_hour"`
+		MsgType  int    `json:"msg_type"`
+		MsgUser  int    `json:"msg_user"`
+		MsgCount int    `json:"msg_count"`
+	}
+
+	// DatacubeInterfaceSummary 接口分析数据
+	DatacubeInterfaceSummary struct {
+		RefDate       string `json:"ref_date"`
+		RefHour       int    `json:"ref_hour"`
+		CallbackCount int    `json:"callback_count"`
+		FailCount     int    `json:"fail_count"`
+		TotalTimeCost int    `json:"total_time_cost"`
+		MaxTimeCost   int    `json:"max_time_cost"`
+	}
+)
+
+const datacubeDateLayout = "2006-01-02"
+
+// splitDateRange 按接口允许的最大天数拆分时间范围
+func splitDateRange(begin, end time.Time, span int) ([][2]time.Time, error) {
+	if begin.IsZero() || end.IsZero() || span < 1 {
+		return nil, codeError(61500)
+	}
+	begin = time.Date(begin.Year(), begin.Month(), begin.Day(), 0, 0, 0, 0, begin.Location())
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, begin.Location())
+	if end.Before(begin) {
+		return nil, codeError(61501)
+	}
+	ranges := make([][2]time.Time, 0)
+	for from := begin; !from.After(end); {
+		to := from.AddDate(0, 0, span-1)
+		if to.After(end) {
+			to = end
+		}
+		ranges = append(ranges, [2]time.Time{from, to})
+		from = to.AddDate(0, 0, 1)
+	}
+	return ranges, nil
+}
+
+// datacube 按最大跨度分批请求统计接口，并将各批次的 list 解析到 list 中
+func (m *Mp) datacube(name string, span int, begin, end time.Time, list interface{}) error {
+	ranges, err := splitDateRange(begin, end, span)
+	if err != nil {
+		return err
+	}
+	rows := make([]json.RawMessage, 0)
+	for i := range ranges {
+		res, err := m.post("/datacube/"+name, map[string]string{
+			"begin_date": ranges[i][0].Format(datacubeDateLayout),
+			"end_date":   ranges[i][1].Format(datacubeDateLayout),
+		})
+		if err != nil {
+			return err
+		}
+		var data struct {
+			List []json.RawMessage `json:"list"`
+		}
+		if err = unmarshalRes(res, &data); err != nil {
+			return err
+		}
+		rows = append(rows, data.List...)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, list)
+}
+
+// GetUserSummary 获取用户增减数据，单次最大跨度 7 天
+func (m *Mp) GetUserSummary(begin, end time.Time) (list []DatacubeUserSummary, err error) {
+	err = m.datacube("getusersummary", 7, begin, end, &list)
+	return
+}
+
+// GetUserCumulate 获取累计用户数据，单次最大跨度 7 天
+func (m *Mp) GetUserCumulate(begin, end time.Time) (list []DatacubeUserCumulate, err error) {
+	err = m.datacube("getusercumulate", 7, begin, end, &list)
+	return
+}
+
+// GetArticleSummary 获取图文群发每日数据，单次最大跨度 1 天
+func (m *Mp) GetArticleSummary(begin, end time.Time) (list []DatacubeArticleSummary, err error) {
+	err = m.datacube("getarticlesummary", 1, begin, end, &list)
+	return
+}
+
+// GetArticleTotal 获取图文群发总数据，单次最大跨度 1 天
+func (m *Mp) GetArticleTotal(begin, end time.Time) (list []DatacubeArticleTotal, err error) {
+	err = m.datacube("getarticletotal", 1, begin, end, &list)
+	return
+}
+
+// GetUserRead 获取图文统计数据，单次最大跨度 3 天
+func (m *Mp) GetUserRead(begin, end time.Time) (list []DatacubeUserRead, err error) {
+	err = m.datacube("getuserread", 3, begin, end, &list)
+	return
+}
+
+// GetUserReadHour 获取图文统计分时数据，单次最大跨度 1 天
+func (m *Mp) GetUserReadHour(begin, end time.Time) (list []DatacubeUserRead, err error) {
+	err = m.datacube("getuserreadhour", 1, begin, end, &list)
+	return
+}
+
+// GetUserShare 获取图文分享转发数据，单次最大跨度 7 天
+func (m *Mp) GetUserShare(begin, end time.Time) (list []DatacubeUserShare, err error) {
+	err = m.datacube("getusershare", 7, begin, end, &list)
+	return
+}
+
+// GetUserShareHour 获取图文分享转发分时数据，单次最大跨度 1 天
+func (m *Mp) GetUserShareHour(begin, end time.Time) (list []DatacubeUserShare, err error) {
+	err = m.datacube("getusersharehour", 1, begin, end, &list)
+	return
+}
+
+// GetUpstreamMsg 获取消息发送概况数据，单次最大跨度 7 天
+func (m *Mp) GetUpstreamMsg(begin, end time.Time) (list []DatacubeUpstreamMsg, err error) {
+	err = m.datacube("getupstreammsg", 7, begin, end, &list)
+	return
+}
+
+// GetUpstreamMsgHour 获取消息发送分时数据，单次最大跨度 1 天
+func (m *Mp) GetUpstreamMsgHour(begin, end time.Time) (list []DatacubeUpstreamMsg, err error) {
+	err = m.datacube("getupstreammsghour", 1, begin, end, &list)
+	return
+}
+
+// GetInterfaceSummary 获取接口分析数据，单次最大跨度 30 天
+func (m *Mp) GetInterfaceSummary(begin, end time.Time) (list []DatacubeInterfaceSummary, err error) {
+	err = m.datacube("getinterfacesummary", 30, begin, end, &list)
+	return
+}
+
+// GetInterfaceSummaryHour 获取接口分析分时数据，单次最大跨度 1 天
+func (m *Mp) GetInterfaceSummaryHour(begin, end time.Time) (list []DatacubeInterfaceSummary, err error) {
+	err = m.datacube("getinterfacesummaryhour", 1, begin, end, &list)
+	return
+}
@@ -0,0 +1,91 @@
+package wechat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestSplitDateRange(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	day := func(d int) time.Time {
+		return time.Date(2021, 1, d, 10, 0, 0, 0, time.Local)
+	}
+	format := func(ranges [][2]time.Time) []string {
+		s := make([]string, 0, len(ranges))
+		for i := range ranges {
+			s = append(s, ranges[i][0].Format(datacubeDateLayout)+"~"+ranges[i][1].Format(datacubeDateLayout))
+		}
+		return s
+	}
+
+	ranges, err := splitDateRange(day(1), day(10), 7)
+	tt.Equal(true, err == nil)
+	tt.Equal([]string{"2021-01-01~2021-01-07", "2021-01-08~2021-01-10"}, format(ranges))
+
+	ranges, err = splitDateRange(day(3), day(5), 1)
+	tt.Equal(true, err == nil)
+	tt.Equal([]string{"2021-01-03~2021-01-03", "2021-01-04~2021-01-04", "2021-01-05~2021-01-05"}, format(ranges))
+
+	ranges, err = splitDateRange(day(3), day(3), 30)
+	tt.Equal(true, err == nil)
+	tt.Equal([]string{"2021-01-03~2021-01-03"}, format(ranges))
+
+	_, err = splitDateRange(day(3), day(2), 7)
+	tt.Equal(61501, ErrorCode(err))
+
+	_, err = splitDateRange(time.Time{}, day(2), 7)
+	tt.Equal(61500, ErrorCode(err))
+}
+
+func TestDatacube(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	mp := &Mp{AppID: "wx_datacube"}
+	e := New(mp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		switch r.JSON("begin_date").String() {
+		case "2021-01-01":
+			return `{"list":[{"ref_date":"2021-01-01","user_source":0,"new_user":1,"cancel_user":0}]}`
+		case "2021-01-08":
+			return `{"list":[{"ref_date":"2021-01-08","user_source":1,"new_user":2,"cancel_user":1}]}`
+		case "2021-01-20":
+			return `{"errcode":61501,"errmsg":"date range error"}`
+		}
+		return `{"list":[]}`
+	})
+	defer api.Close()
+	day := func(d int) time.Time {
+		return time.Date(2021, 1, d, 10, 0, 0, 0, time.Local)
+	}
+
+	list, err := mp.GetUserSummary(day(1), day(10))
+	tt.Equal(true, err == nil)
+	tt.Equal([]DatacubeUserSummary{
+		{RefDate: "2021-01-01", NewUser: 1},
+		{RefDate: "2021-01-08", UserSource: 1, NewUser: 2, CancelUser: 1},
+	}, list)
+	reqs := api.Requests()
+	tt.Equal(2, len(reqs))
+	tt.Equal("/datacube/getusersummary", reqs[0].Path)
+	tt.Equal("2021-01-07", reqs[0].JSON("end_date").String())
+	tt.Equal("2021-01-10", reqs[1].JSON("end_date").String())
+
+	summary, err := mp.GetInterfaceSummaryHour(day(3), day(3))
+	tt.Equal(true, err == nil)
+	tt.Equal(0, len(summary))
+	tt.Equal("/datacube/getinterfacesummaryhour", api.Last().Path)
+
+	_, err = mp.GetUserRead(day(20), day(21))
+	tt.Equal(61501, ErrorCode(err))
+	tt.Equal("日期范围错误", ErrorMsg(err))
+
+	// 日期不合法时不发起请求
+	_, err = mp.GetArticleSummary(day(3), day(2))
+	tt.Equal(61501, ErrorCode(err))
+	tt.Equal("日期范围错误", ErrorMsg(err))
+	_, err = mp.GetUpstreamMsg(time.Time{}, day(2))
+	tt.Equal(61500, ErrorCode(err))
+	tt.Equal("日期格式错误", ErrorMsg(err))
+	tt.Equal(4, len(api.Requests()))
+}
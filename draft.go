@@ -0,0 +1,257 @@
+package wechat
+
+import (
+	"io"
+
+	"github.com/sohaha/zlsgo/zjson"
+)
+
+type (
+	// DraftArticle 草稿箱/发布图文
+	DraftArticle struct {
+		Title              string `json:"title"`
+		Author             string `json:"author,omitempty"`
+		Digest             string `json:"digest,omitempty"`
+		Content            string `json:"content"`
+		ContentSourceURL   string `json:"content_source_url,omitempty"`
+		ThumbMediaID       string `json:"thumb_media_id"`
+		NeedOpenComment    int    `json:"need_open_comment,omitempty"`
+		OnlyFansCanComment int    `json:"only_fans_can_comment,omitempty"`
+		PicCrop2351        string `json:"pic_crop_235_1,omitempty"`
+		PicCrop11          string `json:"pic_crop_1_1,omitempty"`
+		URL                string `json:"url,omitempty"`
+		ThumbURL           string `json:"thumb_url,omitempty"`
+		IsDeleted          bool   `json:"is_deleted,omitempty"`
+	}
+
+	// DraftContent 草稿/已发布图文内容
+	DraftContent struct {
+		NewsItem   []DraftArticle `json:"news_item"`
+		CreateTime int64          `json:"create_time"`
+		UpdateTime int64          `json:"update_time"`
+	}
+
+	// DraftItem 草稿列表项
+	DraftItem struct {
+		MediaID    string       `json:"media_id"`
+		Content    DraftContent `json:"content"`
+		UpdateTime int64        `json:"update_time"`
+	}
+
+	// DraftList 草稿列表
+	DraftList struct {
+		TotalCount int         `json:"total_count"`
+		ItemCount  int         `json:"item_count"`
+		Item       []DraftItem `json:"item"`
+	}
+
+	// PublishArticleDetail 发布成功的图文信息
+	PublishArticleDetail struct {
+		Count int `json:"count" xml:"count"`
+		Item  []struct {
+			Idx        int    `json:"idx" xml:"idx"`
+			ArticleURL string `json:"article_url" xml:"article_url"`
+		} `json:"item" xml:"item"`
+	}
+
+	// PublishStatus 发布状态，同时用于 PUBLISHJOBFINISH 事件
+	PublishStatus struct {
+		PublishID     string               `json:"publish_id" xml:"publish_id"`
+		PublishStatus int                  `json:"publish_status" xml:"publish_status"`
+		ArticleID     string               `json:"article_id" xml:"article_id"`
+		ArticleDetail PublishArticleDetail `json:"article_detail" xml:"article_detail"`
+		FailIdx       []int                `json:"fail_idx" xml:"fail_idx"`
+	}
+
+	// PublishItem 已发布图文列表项
+	PublishItem struct {
+		ArticleID  string       `json:"article_id"`
+		Content    DraftContent `json:"content"`
+		UpdateTime int64        `json:"update_time"`
+	}
+
+	// PublishList 已发布图文列表
+	PublishList struct {
+		TotalCount int           `json:"total_count"`
+		ItemCount  int           `json:"item_count"`
+		Item       []PublishItem `json:"item"`
+	}
+)
+
+const (
+	// EventPublishJobFinish 发布任务完成事件
+	EventPublishJobFinish = "PUBLISHJOBFINISH"
+)
+
+const (
+	PublishSuccess = iota
+	PublishPublishing
+	PublishOriginalFail
+	PublishFail
+	PublishAuditFail
+	PublishDeleted
+	PublishBanned
+)
+
+const batchGetMaxCount = 20
+
+func batchGetParam(offset, count int, noContent bool) map[string]interface{} {
+	if count < 1 || count > batchGetMaxCount {
+		count = batchGetMaxCount
+	}
+	n := 0
+	if noContent {
+		n = 1
+	}
+	return map[string]interface{}{
+		"offset":     offset,
+		"count":      count,
+		"no_content": n,
+	}
+}
+
+// UploadDraftThumb 上传图文封面，返回永久素材 media_id
+func (m *Mp) UploadDraftThumb(fileName string, r io.Reader) (string, error) {
+	e, err := m.checkEngine()
+	if err != nil {
+		return "", err
+	}
+	material, err := e.AddMaterial(MediaTypeImage, fileName, r)
+	if err != nil {
+		return "", err
+	}
+	return material.MediaID, nil
+}
+
+// AddDraft 新建草稿
+func (m *Mp) AddDraft(articles ...DraftArticle) (string, error) {
+	res, err := m.post("/cgi-bin/draft/add", map[string]interface{}{
+		"articles": articles,
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.Get("media_id").String(), nil
+}
+
+// GetDraft 获取草稿
+func (m *Mp) GetDraft(mediaID string) ([]DraftArticle, error) {
+	res, err := m.post("/cgi-bin/draft/get", map[string]string{"media_id": mediaID})
+	if err != nil {
+		return nil, err
+	}
+	var content DraftContent
+	err = unmarshalRes(res, &content)
+	return content.NewsItem, err
+}
+
+// DeleteDraft 删除草稿
+func (m *Mp) DeleteDraft(mediaID string) error {
+	_, err := m.post("/cgi-bin/draft/delete", map[string]string{"media_id": mediaID})
+	return err
+}
+
+// UpdateDraft 修改草稿，index 为要更新的文章在图文中的位置，从 0 开始
+func (m *Mp) UpdateDraft(mediaID string, index int, article DraftArticle) error {
+	_, err := m.post("/cgi-bin/draft/update", map[string]interface{}{
+		"media_id": mediaID,
+		"index":    index,
+		"articles": article,
+	})
+	return err
+}
+
+// GetDraftCount 获取草稿总数
+func (m *Mp) GetDraftCount() (int, error) {
+	res, err := m.get("/cgi-bin/draft/count")
+	if err != nil {
+		return 0, err
+	}
+	return res.Get("total_count").Int(), nil
+}
+
+// BatchGetDraft 获取草稿列表
+func (m *Mp) BatchGetDraft(offset, count int, noContent bool) (list DraftList, err error) {
+	var res *zjson.Res
+	res, err = m.post("/cgi-bin/draft/batchget", batchGetParam(offset, count, noContent))
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &list)
+	return
+}
+
+// DraftForEach 自动翻页遍历草稿，fn 返回 false 时停止
+func (m *Mp) DraftForEach(noContent bool, fn func(item DraftItem) bool) error {
+	return forEachOffset(func(offset int) (int, int, bool, error) {
+		list, err := m.BatchGetDraft(offset, batchGetMaxCount, noContent)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		stop := eachItem(len(list.Item), func(i int) bool { return fn(list.Item[i]) })
+		return list.ItemCount, list.TotalCount, stop, nil
+	})
+}
+
+// SubmitPublish 发布草稿，返回发布任务 ID
+func (m *Mp) SubmitPublish(mediaID string) (string, error) {
+	res, err := m.post("/cgi-bin/freepublish/submit", map[string]string{"media_id": mediaID})
+	if err != nil {
+		return "", err
+	}
+	return res.Get("publish_id").String(), nil
+}
+
+// GetPublish 查询发布状态
+func (m *Mp) GetPublish(publishID string) (status PublishStatus, err error) {
+	var res *zjson.Res
+	res, err = m.post("/cgi-bin/freepublish/get", map[string]string{"publish_id": publishID})
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &status)
+	return
+}
+
+// DeletePublish 删除已发布文章，index 为文章位置，从 1 开始，0 表示删除全部
+func (m *Mp) DeletePublish(articleID string, index int) error {
+	_, err := m.post("/cgi-bin/freepublish/delete", map[string]interface{}{
+		"article_id": articleID,
+		"index":      index,
+	})
+	return err
+}
+
+// GetPublishArticle 通过 article_id 获取已发布文章
+func (m *Mp) GetPublishArticle(articleID string) ([]DraftArticle, error) {
+	res, err := m.post("/cgi-bin/freepublish/getarticle", map[string]string{"article_id": articleID})
+	if err != nil {
+		return nil, err
+	}
+	var content DraftContent
+	err = unmarshalRes(res, &content)
+	return content.NewsItem, err
+}
+
+// BatchGetPublish 获取成功发布列表
+func (m *Mp) BatchGetPublish(offset, count int, noContent bool) (list PublishList, err error) {
+	var res *zjson.Res
+	res, err = m.post("/cgi-bin/freepublish/batchget", batchGetParam(offset, count, noContent))
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &list)
+	return
+}
+
+// PublishForEach 自动翻页遍历成功发布的图文，fn 返回 false 时停止
+func (m *Mp) PublishForEach(noContent bool, fn func(item PublishItem) bool) error {
+	return forEachOffset(func(offset int) (int, int, bool, error) {
+		list, err := m.BatchGetPublish(offset, batchGetMaxCount, noContent)
+		if err != nil {
+			return 0, 0, false, err
+		}
+		stop := eachItem(len(list.Item), func(i int) bool { return fn(list.Item[i]) })
+		return list.ItemCount, list.TotalCount, stop, nil
+	})
+}
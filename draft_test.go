@@ -0,0 +1,54 @@
+package wechat
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestDraftForEach(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	mp := &Mp{AppID: "wx_draft_each"}
+	e := New(mp)
+	total := 45
+	api := newMockAPI(e, func(r mockRequest) string {
+		offset, count := r.JSON("offset").Int(), r.JSON("count").Int()
+		items := ""
+		n := 0
+		for i := offset; i < offset+count && i < total; i++ {
+			if items != "" {
+				items += ","
+			}
+			items += `{"media_id":"m` + strconv.Itoa(i) + `"}`
+			n++
+		}
+		return `{"total_count":` + strconv.Itoa(total) + `,"item_count":` + strconv.Itoa(n) + `,"item":[` + items + `]}`
+	})
+	defer api.Close()
+
+	var ids []string
+	err := mp.DraftForEach(true, func(item DraftItem) bool {
+		ids = append(ids, item.MediaID)
+		return true
+	})
+	tt.Equal(true, err == nil)
+	tt.Equal(45, len(ids))
+	tt.Equal("m44", ids[44])
+	reqs := api.Requests()
+	tt.Equal(3, len(reqs))
+	tt.Equal("/cgi-bin/draft/batchget", reqs[2].Path)
+	tt.Equal(40, reqs[2].JSON("offset").Int())
+	tt.Equal(20, reqs[2].JSON("count").Int())
+	tt.Equal(1, reqs[2].JSON("no_content").Int())
+
+	ids = ids[:0]
+	err = mp.PublishForEach(false, func(item PublishItem) bool {
+		ids = append(ids, "")
+		return len(ids) < 25
+	})
+	tt.Equal(true, err == nil)
+	tt.Equal(25, len(ids))
+	tt.Equal("/cgi-bin/freepublish/batchget", api.Last().Path)
+	tt.Equal(5, len(api.Requests()))
+}
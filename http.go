@@ -1,7 +1,10 @@
 package wechat
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"io/ioutil"
 	"strings"
 
 	"github.com/sohaha/zlsgo/zhttp"
@@ -53,15 +56,73 @@ func (e *Engine) HttpAccessTokenPostRaw(url string, v ...interface{}) (j []byte,
 	b, err := httpProcess(http.Post(url, append(transformSendData(v), zhttp.QueryParam{"access_token": token})...))
 
 	if err == errNoJSON {
-		return b, nil
+		if !isJSONBody(b) {
+			return b, nil
+		}
+		err = nil
 	}
 
-	if e.checkTokenExpiration(err) {
-		return e.HttpAccessTokenPostRaw(url, v...)
+	if err != nil {
+		return nil, err
 	}
 
-	_, err = CheckResError(b)
-	return nil, err
+	if _, err = CheckResError(b); err != nil {
+		if e.checkTokenExpiration(err) {
+			return e.HttpAccessTokenPostRaw(url, v...)
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+func (e *Engine) HttpAccessTokenGetRaw(url string, v ...interface{}) (j []byte, err error) {
+	token, err := e.GetAccessToken()
+	if err != nil {
+		return
+	}
+
+	b, err := httpProcess(http.Get(url, append(transformSendData(v), zhttp.QueryParam{"access_token": token})...))
+
+	if err == errNoJSON {
+		if !isJSONBody(b) {
+			return b, nil
+		}
+		err = nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = CheckResError(b); err != nil {
+		if e.checkTokenExpiration(err) {
+			return e.HttpAccessTokenGetRaw(url, v...)
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+// httpAccessTokenUpload 以 multipart 方式上传文件，重试时会重新构造文件流
+func (e *Engine) httpAccessTokenUpload(url, fieldName, fileName string, data []byte, v ...interface{}) (j *zjson.Res, err error) {
+	var token string
+	token, err = e.GetAccessToken()
+	if err != nil {
+		return
+	}
+	file := zhttp.UploadFile{
+		FieldName: fieldName,
+		FileName:  fileName,
+		File:      ioutil.NopCloser(bytes.NewReader(data)),
+	}
+	param := make([]interface{}, 0, len(v)+2)
+	param = append(param, v...)
+	param = append(param, file, zhttp.QueryParam{"access_token": token})
+	j, err = httpResProcess(http.Post(url, param...))
+	if e.checkTokenExpiration(err) {
+		return e.httpAccessTokenUpload(url, fieldName, fileName, data, v...)
+	}
+	return
 }
 
 func httpResProcess(r *zhttp.Res, e error) (*zjson.Res, error) {
@@ -81,12 +142,30 @@ func httpProcess(r *zhttp.Res, e error) ([]byte, error) {
 	}
 	bytes := r.Bytes()
 	ctype := r.Response().Header.Get("Content-Type")
-	if strings.Contains(ctype, "image/") {
+	if isBinaryContentType(ctype) {
 		e = errNoJSON
 	}
 	return bytes, e
 }
 
+// isBinaryContentType 非 JSON/XML/文本类型的响应均视为二进制文件
+func isBinaryContentType(ctype string) bool {
+	ctype = strings.ToLower(strings.TrimSpace(ctype))
+	if ctype == "" {
+		return false
+	}
+	if strings.Contains(ctype, "json") || strings.Contains(ctype, "xml") || strings.HasPrefix(ctype, "text/") {
+		return false
+	}
+	return true
+}
+
+// isJSONBody 响应内容是否为 JSON，部分接口出错时仍会返回图片的 Content-Type
+func isJSONBody(b []byte) bool {
+	b = bytes.TrimSpace(b)
+	return len(b) > 0 && b[0] == '{' && json.Valid(b)
+}
+
 func httpPayProcess(r *zhttp.Res, e error) (ztype.Map, error) {
 	b, err := httpProcess(r, e)
 	if err != nil {
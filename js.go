@@ -1,7 +1,10 @@
 package wechat
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/sohaha/zlsgo/zhttp"
@@ -13,12 +16,101 @@ type (
 	JsSign struct {
 		AppID     string `json:"appid"`
 		Timestamp int64  `json:"timestamp"`
-		NonceStr  string `json:"nonce_str"`
+		NonceStr  string `json:"nonceStr"`
+		Signature string `json:"signature"`
+	}
+
+	// JSConfig wx.config 配置
+	JSConfig struct {
+		Debug       bool     `json:"debug"`
+		AppID       string   `json:"appId"`
+		Timestamp   int64    `json:"timestamp"`
+		NonceStr    string   `json:"nonceStr"`
+		Signature   string   `json:"signature"`
+		JsAPIList   []string `json:"jsApiList"`
+		OpenTagList []string `json:"openTagList,omitempty"`
+	}
+
+	JSConfigOption func(*JSConfig)
+
+	// AgentConfigSign 企业微信 wx.agentConfig 签名
+	AgentConfigSign struct {
+		CorpID    string `json:"corpid"`
+		AgentID   string `json:"agentid"`
+		Timestamp int64  `json:"timestamp"`
+		NonceStr  string `json:"nonceStr"`
 		Signature string `json:"signature"`
 	}
 )
 
+var (
+	ErrOnlySupportsQy  = errors.New("only supports qy")
+	ErrJsURLInvalid    = errors.New("invalid page url")
+	ErrJsURLNotAllowed = errors.New("page url domain is not allowed")
+)
+
+// WithJSAPIList 需要使用的 JS 接口列表
+func WithJSAPIList(apis ...string) JSConfigOption {
+	return func(c *JSConfig) {
+		c.JsAPIList = append(c.JsAPIList, apis...)
+	}
+}
+
+// WithOpenTagList 需要使用的开放标签列表
+func WithOpenTagList(tags ...string) JSConfigOption {
+	return func(c *JSConfig) {
+		c.OpenTagList = append(c.OpenTagList, tags...)
+	}
+}
+
+// WithJSDebug 开启调试模式
+func WithJSDebug(debug bool) JSConfigOption {
+	return func(c *JSConfig) {
+		c.Debug = debug
+	}
+}
+
+// GetJSConfig 生成 wx.config 配置
+func (e *Engine) GetJSConfig(url string, opts ...JSConfigOption) (JSConfig, error) {
+	sign, err := e.GetJsSign(url)
+	if err != nil {
+		return JSConfig{}, err
+	}
+	c := JSConfig{
+		AppID:     sign.AppID,
+		Timestamp: sign.Timestamp,
+		NonceStr:  sign.NonceStr,
+		Signature: sign.Signature,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if len(c.JsAPIList) == 0 {
+		// jsApiList 不能为空，仅使用开放标签时任意填一个接口即可
+		c.JsAPIList = []string{"checkJsApi"}
+	}
+	return c, nil
+}
+
+// jsPageURL 去除页面地址的 # 部分并校验域名，domains 支持 *.example.com 通配子域名
+func jsPageURL(pageURL string, domains []string) (string, error) {
+	if i := strings.Index(pageURL, "#"); i >= 0 {
+		pageURL = pageURL[:i]
+	}
+	u, err := url.Parse(pageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", ErrJsURLInvalid
+	}
+	if !matchDomain(u.Hostname(), domains) {
+		return "", ErrJsURLNotAllowed
+	}
+	return pageURL, nil
+}
+
 func (e *Engine) GetJsSign(url string) (JsSign, error) {
+	if i := strings.Index(url, "#"); i >= 0 {
+		url = url[:i]
+	}
 	jsapiTicket, err := e.GetJsapiTicket()
 	if err != nil {
 		return JsSign{}, err
@@ -26,17 +118,20 @@ func (e *Engine) GetJsSign(url string) (JsSign, error) {
 
 	timestamp := time.Now().Unix()
 	noncestr := zstring.Rand(16)
-	signature := fmt.Sprintf("jsapi_ticket=%s&noncestr=%s&timestamp=%d&url=%s", jsapiTicket, noncestr, timestamp, url)
-	signature = sha1Signature(signature)
 
 	return JsSign{
 		AppID:     e.GetAppID(),
 		NonceStr:  noncestr,
 		Timestamp: timestamp,
-		Signature: signature,
+		Signature: jsSignature(jsapiTicket, noncestr, timestamp, url),
 	}, nil
 }
 
+// jsSignature wx.config 与 wx.agentConfig 使用相同的签名算法
+func jsSignature(ticket, noncestr string, timestamp int64, url string) string {
+	return sha1Signature(fmt.Sprintf("jsapi_ticket=%s&noncestr=%s&timestamp=%d&url=%s", ticket, noncestr, timestamp, url))
+}
+
 func (e *Engine) SetJsapiTicket(ticket string, expiresIn uint) error {
 	e.cache.Set(cacheJsapiTicket, ticket, expiresIn-60)
 	return nil
@@ -67,3 +162,77 @@ func (e *Engine) GetJsapiTicket() (string, error) {
 	return data.(string), nil
 
 }
+
+func (e *Engine) qyConfig() (*Qy, error) {
+	conf, ok := e.config.(*Qy)
+	if !ok {
+		return nil, ErrOnlySupportsQy
+	}
+	return conf, nil
+}
+
+// SetAgentJsapiTicket 设置当前应用的 jsapi_ticket
+func (e *Engine) SetAgentJsapiTicket(ticket string, expiresIn uint) error {
+	conf, err := e.qyConfig()
+	if err != nil {
+		return err
+	}
+	e.cache.Set(cacheAgentJsapiTicket+conf.AgentID, ticket, expiresIn-60)
+	return nil
+}
+
+// GetAgentJsapiTicket 获取企业微信当前应用的 jsapi_ticket，用于 wx.agentConfig
+func (e *Engine) GetAgentJsapiTicket() (string, error) {
+	conf, err := e.qyConfig()
+	if err != nil {
+		return "", err
+	}
+	data, err := e.cache.MustGet(cacheAgentJsapiTicket+conf.AgentID, func(set func(data interface{}, lifeSpan time.Duration, interval ...bool)) (err error) {
+		var res *zhttp.Res
+		res, err = conf.getAgentJsapiTicket()
+		if err != nil {
+			return
+		}
+		var json *zjson.Res
+		json, err = CheckResError(res.Bytes())
+		if err != nil {
+			return
+		}
+		ticket := json.Get("ticket").String()
+		if ticket == "" {
+			return errors.New("ticket parsing failed")
+		}
+		set(ticket, time.Duration(json.Get("expires_in").Int()-200)*time.Second)
+		return
+	})
+	if err != nil {
+		return "", err
+	}
+	return data.(string), nil
+}
+
+// GetAgentConfigSign 生成企业微信 wx.agentConfig 签名
+func (e *Engine) GetAgentConfigSign(url string) (AgentConfigSign, error) {
+	conf, err := e.qyConfig()
+	if err != nil {
+		return AgentConfigSign{}, err
+	}
+	if i := strings.Index(url, "#"); i >= 0 {
+		url = url[:i]
+	}
+	ticket, err := e.GetAgentJsapiTicket()
+	if err != nil {
+		return AgentConfigSign{}, err
+	}
+
+	timestamp := time.Now().Unix()
+	noncestr := zstring.Rand(16)
+
+	return AgentConfigSign{
+		CorpID:    conf.CorpID,
+		AgentID:   conf.AgentID,
+		Timestamp: timestamp,
+		NonceStr:  noncestr,
+		Signature: jsSignature(ticket, noncestr, timestamp, url),
+	}, nil
+}
@@ -0,0 +1,70 @@
+package wechat
+
+import (
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestJsPageURL(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	domains := []string{"example.com", "*.example.net"}
+	for raw, want := range map[string]string{
+		"https://example.com/a?b=1#/c":     "https://example.com/a?b=1",
+		"http://example.com:8080/":         "http://example.com:8080/",
+		"https://m.example.net/page#hash":  "https://m.example.net/page",
+		"https://evil.com/?r=example.com":  "",
+		"https://example.com.evil.com/":    "",
+		"javascript:alert(1)//example.com": "",
+	} {
+		u, err := jsPageURL(raw, domains)
+		tt.Equal(want, u)
+		tt.Equal(want == "", err != nil)
+	}
+}
+
+func TestJsSignature(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	tt.Equal("0f9de62fce790f9a083d5c99e95740ceb90c27ed", jsSignature("sM4AOVdWfPE4DxkXGEs8VMCPGGVi4C3VM0P37wVUCFvkVAy_90u5h9nbSlYy3-Sl-HhTdfl2fzFy1AOcHKP7qg", "Wm3WZYTPz0wzccnW", 1414587457, "http://mp.weixin.qq.com?params=value"))
+}
+
+func TestAgentConfigSign(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	e := New(&Qy{CorpID: "wx_agent_config", AgentID: "1000002"})
+	api := newMockAPI(e, func(r mockRequest) string {
+		switch r.Path {
+		case "/cgi-bin/get_jsapi_ticket":
+			return `{"errcode":0,"errmsg":"ok","ticket":"corp_ticket","expires_in":7200}`
+		case "/cgi-bin/ticket/get":
+			if r.Query.Get("type") == "agent_config" {
+				return `{"errcode":0,"errmsg":"ok","ticket":"agent_ticket","expires_in":7200}`
+			}
+		}
+		return `{"errcode":40001,"errmsg":"invalid"}`
+	})
+	defer api.Close()
+
+	ticket, err := e.GetJsapiTicket()
+	tt.Equal(true, err == nil)
+	tt.Equal("corp_ticket", ticket)
+	ticket, err = e.GetAgentJsapiTicket()
+	tt.Equal(true, err == nil)
+	tt.Equal("agent_ticket", ticket)
+	tt.Equal("ACCESS_TOKEN", api.Last().Query.Get("access_token"))
+
+	// 两种 ticket 分别缓存
+	ticket, _ = e.cache.GetString(cacheJsapiTicket)
+	tt.Equal("corp_ticket", ticket)
+	ticket, _ = e.cache.GetString(cacheAgentJsapiTicket + "1000002")
+	tt.Equal("agent_ticket", ticket)
+
+	sign, err := e.GetAgentConfigSign("https://example.com/a?b=1#/c")
+	tt.Equal(true, err == nil)
+	tt.Equal(2, len(api.Requests()))
+	tt.Equal("wx_agent_config", sign.CorpID)
+	tt.Equal("1000002", sign.AgentID)
+	tt.Equal(jsSignature("agent_ticket", sign.NonceStr, sign.Timestamp, "https://example.com/a?b=1"), sign.Signature)
+
+	_, err = New(&Mp{AppID: "wx_agent_config_mp"}).GetAgentConfigSign("https://example.com/")
+	tt.Equal(ErrOnlySupportsQy, err)
+}
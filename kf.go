@@ -0,0 +1,262 @@
+package wechat
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sohaha/zlsgo/zhttp"
+	"github.com/sohaha/zlsgo/zjson"
+)
+
+type (
+	// KfAccount 客服账号
+	KfAccount struct {
+		KfAccount        string `json:"kf_account"`
+		KfNick           string `json:"kf_nick"`
+		KfID             string `json:"kf_id"`
+		KfHeadImgURL     string `json:"kf_headimgurl"`
+		KfWx             string `json:"kf_wx"`
+		InviteWx         string `json:"invite_wx"`
+		InviteExpireTime int64  `json:"invite_expire_time"`
+		InviteStatus     string `json:"invite_status"`
+	}
+
+	// KfOnline 在线客服
+	KfOnline struct {
+		KfAccount    string `json:"kf_account"`
+		Status       int    `json:"status"`
+		KfID         string `json:"kf_id"`
+		AcceptedCase int    `json:"accepted_case"`
+	}
+
+	// KfSession 客服会话
+	KfSession struct {
+		KfAccount  string `json:"kf_account"`
+		OpenID     string `json:"openid"`
+		CreateTime int64  `json:"createtime"`
+	}
+
+	// KfWaitCase 未接入会话
+	KfWaitCase struct {
+		OpenID     string `json:"openid"`
+		LatestTime int64  `json:"latest_time"`
+	}
+
+	// KfMsgRecord 客服聊天记录
+	KfMsgRecord struct {
+		OpenID   string `json:"openid"`
+		OperCode int    `json:"opercode"`
+		Text     string `json:"text"`
+		Time     int64  `json:"time"`
+		Worker   string `json:"worker"`
+	}
+)
+
+const (
+	kfMsgRecordNumber = 10000
+	kfMsgRecordSpan   = 24 * time.Hour
+)
+
+// checkKfAccount 校验客服账号，格式为 账号前缀@公众号微信号
+func checkKfAccount(account string) error {
+	i := strings.Index(account, "@")
+	if i <= 0 || i == len(account)-1 {
+		return codeError(61451)
+	}
+	prefix := account[:i]
+	if len(prefix) > 10 {
+		return codeError(61454)
+	}
+	for _, c := range prefix {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return codeError(61455)
+		}
+	}
+	return nil
+}
+
+// AddKfAccount 添加客服账号
+func (m *Mp) AddKfAccount(account, nickname string) error {
+	return m.kfAccountPost("/customservice/kfaccount/add", account, map[string]string{
+		"kf_account": account,
+		"nickname":   nickname,
+	})
+}
+
+// UpdateKfAccount 设置客服昵称
+func (m *Mp) UpdateKfAccount(account, nickname string) error {
+	return m.kfAccountPost("/customservice/kfaccount/update", account, map[string]string{
+		"kf_account": account,
+		"nickname":   nickname,
+	})
+}
+
+// InviteKfWorker 邀请绑定客服账号
+func (m *Mp) InviteKfWorker(account, inviteWx string) error {
+	return m.kfAccountPost("/customservice/kfaccount/inviteworker", account, map[string]string{
+		"kf_account": account,
+		"invite_wx":  inviteWx,
+	})
+}
+
+func (m *Mp) kfAccountPost(path, account string, data map[string]string) error {
+	if err := checkKfAccount(account); err != nil {
+		return err
+	}
+	_, err := m.post(path, data)
+	return err
+}
+
+// DeleteKfAccount 删除客服账号
+func (m *Mp) DeleteKfAccount(account string) error {
+	_, err := m.get("/customservice/kfaccount/del", zhttp.QueryParam{"kf_account": account})
+	return err
+}
+
+// UploadKfHeadImg 上传客服头像，仅支持 jpg 格式
+func (m *Mp) UploadKfHeadImg(account, fileName string, r io.Reader) error {
+	e, err := m.checkEngine()
+	if err != nil {
+		return err
+	}
+	if ext := strings.ToLower(filepath.Ext(fileName)); ext != ".jpg" && ext != ".jpeg" {
+		return codeError(61457)
+	}
+	data, err := readMedia(r, mediaLimits[MediaTypeImage].size, 40009)
+	if err != nil {
+		return err
+	}
+	_, err = e.httpAccessTokenUpload(e.apiURL+"/customservice/kfaccount/uploadheadimg", "media", fileName, data,
+		zhttp.QueryParam{"kf_account": account})
+	return err
+}
+
+// GetKfList 获取所有客服账号
+func (m *Mp) GetKfList() (list []KfAccount, err error) {
+	var res *zjson.Res
+	res, err = m.get("/cgi-bin/customservice/getkflist")
+	if err != nil {
+		return
+	}
+	var data struct {
+		KfList []KfAccount `json:"kf_list"`
+	}
+	err = unmarshalRes(res, &data)
+	return data.KfList, err
+}
+
+// GetOnlineKfList 获取在线客服
+func (m *Mp) GetOnlineKfList() (list []KfOnline, err error) {
+	var res *zjson.Res
+	res, err = m.get("/cgi-bin/customservice/getonlinekflist")
+	if err != nil {
+		return
+	}
+	var data struct {
+		KfOnlineList []KfOnline `json:"kf_online_list"`
+	}
+	err = unmarshalRes(res, &data)
+	return data.KfOnlineList, err
+}
+
+// CreateKfSession 创建会话
+func (m *Mp) CreateKfSession(account, openid string) error {
+	_, err := m.post("/customservice/kfsession/create", map[string]string{
+		"kf_account": account,
+		"openid":     openid,
+	})
+	return err
+}
+
+// CloseKfSession 关闭会话
+func (m *Mp) CloseKfSession(account, openid string) error {
+	_, err := m.post("/customservice/kfsession/close", map[string]string{
+		"kf_account": account,
+		"openid":     openid,
+	})
+	return err
+}
+
+// GetKfSession 获取客户会话状态
+func (m *Mp) GetKfSession(openid string) (session KfSession, err error) {
+	var res *zjson.Res
+	res, err = m.get("/customservice/kfsession/getsession", zhttp.QueryParam{"openid": openid})
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &session)
+	session.OpenID = openid
+	return
+}
+
+// GetKfSessionList 获取客服会话列表
+func (m *Mp) GetKfSessionList(account string) (list []KfSession, err error) {
+	var res *zjson.Res
+	res, err = m.get("/customservice/kfsession/getsessionlist", zhttp.QueryParam{"kf_account": account})
+	if err != nil {
+		return
+	}
+	var data struct {
+		SessionList []KfSession `json:"sessionlist"`
+	}
+	err = unmarshalRes(res, &data)
+	for i := range data.SessionList {
+		data.SessionList[i].KfAccount = account
+	}
+	return data.SessionList, err
+}
+
+// GetKfWaitCase 获取未接入会话列表
+func (m *Mp) GetKfWaitCase() (count int, list []KfWaitCase, err error) {
+	var res *zjson.Res
+	res, err = m.get("/customservice/kfsession/getwaitcase")
+	if err != nil {
+		return
+	}
+	var data struct {
+		Count        int          `json:"count"`
+		WaitCaseList []KfWaitCase `json:"waitcaselist"`
+	}
+	err = unmarshalRes(res, &data)
+	return data.Count, data.WaitCaseList, err
+}
+
+// KfMsgRecordForEach 获取聊天记录，时间范围按 24 小时拆分并自动翻页，fn 返回 false 时停止
+func (m *Mp) KfMsgRecordForEach(start, end time.Time, fn func(record KfMsgRecord) bool) error {
+	for from := start; from.Before(end); from = from.Add(kfMsgRecordSpan) {
+		to := from.Add(kfMsgRecordSpan)
+		if to.After(end) {
+			to = end
+		}
+		stopped := false
+		msgID := int64(1)
+		err := forEachCursor(func() (bool, bool, error) {
+			res, err := m.post("/customservice/msgrecord/getmsglist", map[string]interface{}{
+				"starttime": from.Unix(),
+				"endtime":   to.Unix(),
+				"msgid":     msgID,
+				"number":    kfMsgRecordNumber,
+			})
+			if err != nil {
+				return false, false, err
+			}
+			var data struct {
+				RecordList []KfMsgRecord `json:"recordlist"`
+				Number     int           `json:"number"`
+				MsgID      int64         `json:"msgid"`
+			}
+			if err = unmarshalRes(res, &data); err != nil {
+				return false, false, err
+			}
+			stopped = eachItem(len(data.RecordList), func(i int) bool { return fn(data.RecordList[i]) })
+			msgID = data.MsgID
+			return data.Number >= kfMsgRecordNumber && data.MsgID != 0, stopped, nil
+		})
+		if err != nil || stopped {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package wechat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestKfAccount(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	for account, code := range map[string]int{
+		"test1@test":       0,
+		"test":             61451,
+		"@test":            61451,
+		"test@":            61451,
+		"abcdefghijk@test": 61454,
+		"te_st@test":       61455,
+	} {
+		err := checkKfAccount(account)
+		if code == 0 {
+			tt.Equal(true, err == nil)
+			continue
+		}
+		tt.Equal(code, ErrorCode(err))
+	}
+}
+
+func TestCustomMessageData(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	data := customMessageData("openid", CustomText{Content: "hi"}, "kf@test")
+	tt.Equal("text", data["msgtype"])
+	tt.Equal(CustomText{Content: "hi"}, data["text"])
+	tt.Equal(map[string]string{"kf_account": "kf@test"}, data["customservice"])
+
+	data = customMessageData("openid", CustomMenu{}, "")
+	tt.Equal("msgmenu", data["msgtype"])
+	_, ok := data["customservice"]
+	tt.Equal(false, ok)
+}
+
+func TestKfMsgRecordForEach(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	mp := &Mp{AppID: "wx_kf_record"}
+	e := New(mp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		if r.JSON("msgid").Int() == 1 && r.JSON("starttime").Int() == 0 {
+			return `{"recordlist":[{"openid":"a"},{"openid":"b"}],"number":10000,"msgid":20}`
+		}
+		return `{"recordlist":[{"openid":"c"}],"number":1,"msgid":21}`
+	})
+	defer api.Close()
+
+	var openids []string
+	err := mp.KfMsgRecordForEach(time.Unix(0, 0), time.Unix(0, 0).Add(36*time.Hour), func(record KfMsgRecord) bool {
+		openids = append(openids, record.OpenID)
+		return true
+	})
+	tt.Equal(true, err == nil)
+	tt.Equal([]string{"a", "b", "c", "c"}, openids)
+	reqs := api.Requests()
+	tt.Equal(3, len(reqs))
+	tt.Equal("/customservice/msgrecord/getmsglist", reqs[0].Path)
+	tt.Equal(86400, reqs[0].JSON("endtime").Int())
+	tt.Equal(20, reqs[1].JSON("msgid").Int())
+	tt.Equal(86400, reqs[2].JSON("starttime").Int())
+	tt.Equal(129600, reqs[2].JSON("endtime").Int())
+
+	openids = openids[:0]
+	err = mp.KfMsgRecordForEach(time.Unix(0, 0), time.Unix(0, 0).Add(36*time.Hour), func(record KfMsgRecord) bool {
+		openids = append(openids, record.OpenID)
+		return false
+	})
+	tt.Equal(true, err == nil)
+	tt.Equal([]string{"a"}, openids)
+	tt.Equal(4, len(api.Requests()))
+}
+
+func TestKfSessionRequest(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	mp := &Mp{AppID: "wx_kf_session"}
+	e := New(mp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		if r.Path == "/customservice/kfsession/getsessionlist" {
+			return `{"sessionlist":[{"openid":"openid","createtime":123}]}`
+		}
+		return ""
+	})
+	defer api.Close()
+
+	tt.Equal(true, mp.CreateKfSession("kf@test", "openid") == nil)
+	req := api.Last()
+	tt.Equal("/customservice/kfsession/create", req.Path)
+	tt.Equal("kf@test", req.JSON("kf_account").String())
+	tt.Equal("openid", req.JSON("openid").String())
+
+	tt.Equal(true, mp.DeleteKfAccount("kf@test") == nil)
+	req = api.Last()
+	tt.Equal("GET", req.Method)
+	tt.Equal("kf@test", req.Query.Get("kf_account"))
+
+	list, err := mp.GetKfSessionList("kf@test")
+	tt.Equal(true, err == nil)
+	tt.Equal(1, len(list))
+	tt.Equal("kf@test", list[0].KfAccount)
+	tt.Equal("openid", list[0].OpenID)
+}
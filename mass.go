@@ -0,0 +1,295 @@
+package wechat
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/sohaha/zlsgo/zjson"
+	"github.com/sohaha/zlsgo/zstring"
+)
+
+type (
+	// MassMessage 群发消息
+	MassMessage interface {
+		massType() string
+	}
+
+	// MassMpNews 图文消息
+	MassMpNews struct {
+		MediaID string `json:"media_id"`
+	}
+
+	// MassText 文本消息
+	MassText struct {
+		Content string `json:"content"`
+	}
+
+	// MassVoice 语音消息
+	MassVoice struct {
+		MediaID string `json:"media_id"`
+	}
+
+	// MassImages 图片消息
+	MassImages struct {
+		MediaIDs           []string `json:"media_ids"`
+		Recommend          string   `json:"recommend,omitempty"`
+		NeedOpenComment    int      `json:"need_open_comment,omitempty"`
+		OnlyFansCanComment int      `json:"only_fans_can_comment,omitempty"`
+	}
+
+	// MassVideo 视频消息
+	MassVideo struct {
+		MediaID string `json:"media_id"`
+	}
+
+	// MassCard 卡券消息
+	MassCard struct {
+		CardID string `json:"card_id"`
+	}
+
+	// MassResult 群发结果
+	MassResult struct {
+		MsgID     int64 `json:"msg_id"`
+		MsgDataID int64 `json:"msg_data_id"`
+	}
+
+	// MassOptions 群发选项
+	MassOptions struct {
+		// ClientMsgID 群发幂等 ID，为空时根据消息内容生成
+		ClientMsgID string
+		// SendIgnoreReprint 图文被判定为转载时是否继续群发
+		SendIgnoreReprint bool
+	}
+
+	MassOption func(*MassOptions)
+
+	// MassCopyrightCheckItem 单篇图文原创校验结果
+	MassCopyrightCheckItem struct {
+		ArticleIdx            int
+		UserDeclareState      int
+		AuditState            int
+		OriginalArticleURL    string `xml:"OriginalArticleUrl"`
+		OriginalArticleType   int
+		CanReprint            int
+		NeedReplaceContent    int
+		NeedShowReprintSource int
+	}
+
+	// MassSendJobResult 群发结果事件 MASSSENDJOBFINISH
+	MassSendJobResult struct {
+		MsgID                int64 `xml:"MsgID"`
+		Status               string
+		TotalCount           int
+		FilterCount          int
+		SentCount            int
+		ErrorCount           int
+		CopyrightCheckResult struct {
+			Count      int
+			ResultList []MassCopyrightCheckItem `xml:"ResultList>item"`
+			CheckState int
+		}
+		ArticleURLResult struct {
+			Count      int
+			ResultList []struct {
+				ArticleIdx int
+				ArticleURL string `xml:"ArticleUrl"`
+			} `xml:"ResultList>item"`
+		} `xml:"ArticleUrlResult"`
+	}
+)
+
+const (
+	// EventMassSendJobFinish 群发结果事件
+	EventMassSendJobFinish = "MASSSENDJOBFINISH"
+
+	massMaxOpenID = 10000
+	massMinOpenID = 2
+)
+
+var (
+	ErrMassOpenID = errors.New("mass send requires at least 2 openids")
+)
+
+func (MassMpNews) massType() string { return "mpnews" }
+func (MassText) massType() string   { return "text" }
+func (MassVoice) massType() string  { return "voice" }
+func (MassImages) massType() string { return "image" }
+func (MassVideo) massType() string  { return "mpvideo" }
+func (MassCard) massType() string   { return "wxcard" }
+
+// WithMassClientMsgID 指定群发幂等 ID
+func WithMassClientMsgID(id string) MassOption {
+	return func(o *MassOptions) {
+		o.ClientMsgID = id
+	}
+}
+
+// WithMassSendIgnoreReprint 图文被判定为转载时继续群发
+func WithMassSendIgnoreReprint() MassOption {
+	return func(o *MassOptions) {
+		o.SendIgnoreReprint = true
+	}
+}
+
+func massData(msg MassMessage, opts []MassOption) (map[string]interface{}, MassOptions) {
+	o := MassOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	msgType := msg.massType()
+	key := msgType
+	if msgType == "image" {
+		// 图片消息的内容字段为 images
+		key = "images"
+	}
+	data := map[string]interface{}{
+		"msgtype": msgType,
+		key:       msg,
+	}
+	if msgType == "mpnews" {
+		ignore := 0
+		if o.SendIgnoreReprint {
+			ignore = 1
+		}
+		data["send_ignore_reprint"] = ignore
+	}
+	return data, o
+}
+
+// massClientMsgID 未指定幂等 ID 时使用发送内容的摘要，重试同一群发时不会重复发送
+func massClientMsgID(data map[string]interface{}) string {
+	b, _ := json.Marshal(data)
+	return zstring.Md5(zstring.Bytes2String(b))
+}
+
+// chunkOpenIDs 按单次群发上限拆分 OpenID，且保证每组至少 2 个
+func chunkOpenIDs(openids []string, size int) [][]string {
+	chunks := make([][]string, 0, len(openids)/size+1)
+	for len(openids) > 0 {
+		n := size
+		if len(openids) < n {
+			n = len(openids)
+		} else if rest := len(openids) - n; rest > 0 && rest < massMinOpenID {
+			n -= massMinOpenID - rest
+		}
+		chunks = append(chunks, openids[:n])
+		openids = openids[n:]
+	}
+	return chunks
+}
+
+// massPost 群发接口，clientmsgid 重复时返回错误码 45065
+func (m *Mp) massPost(path string, data map[string]interface{}) (result MassResult, err error) {
+	var res *zjson.Res
+	res, err = m.post(path, data)
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &result)
+	return
+}
+
+// MassSendAll 根据标签群发，tagID 为 0 时发送给全部用户
+func (m *Mp) MassSendAll(tagID int, msg MassMessage, opts ...MassOption) (MassResult, error) {
+	data, o := massData(msg, opts)
+	filter := map[string]interface{}{"is_to_all": tagID == 0}
+	if tagID != 0 {
+		filter["tag_id"] = tagID
+	}
+	data["filter"] = filter
+	if o.ClientMsgID == "" {
+		o.ClientMsgID = massClientMsgID(data)
+	}
+	data["clientmsgid"] = o.ClientMsgID
+	return m.massPost("/cgi-bin/message/mass/sendall", data)
+}
+
+// MassSend 根据 OpenID 列表群发，超过单次上限时自动分批发送，
+// 重试时已发送过的分批（错误码 45065）会被跳过，其结果为空
+func (m *Mp) MassSend(openids []string, msg MassMessage, opts ...MassOption) ([]MassResult, error) {
+	if len(openids) < massMinOpenID {
+		return nil, ErrMassOpenID
+	}
+	chunks := chunkOpenIDs(openids, massMaxOpenID)
+	results := make([]MassResult, 0, len(chunks))
+	for i := range chunks {
+		data, o := massData(msg, opts)
+		data["touser"] = chunks[i]
+		if o.ClientMsgID == "" {
+			o.ClientMsgID = massClientMsgID(data)
+		} else if len(chunks) > 1 {
+			o.ClientMsgID += "_" + strconv.Itoa(i)
+		}
+		data["clientmsgid"] = o.ClientMsgID
+		result, err := m.massPost("/cgi-bin/message/mass/send", data)
+		if ErrorCode(err) == 45065 {
+			err = nil
+		}
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// MassPreview 预览群发消息，towxname 优先于 openid
+func (m *Mp) MassPreview(openid, towxname string, msg MassMessage) error {
+	data, _ := massData(msg, nil)
+	delete(data, "send_ignore_reprint")
+	if towxname != "" {
+		data["towxname"] = towxname
+	} else {
+		data["touser"] = openid
+	}
+	_, err := m.post("/cgi-bin/message/mass/preview", data)
+	return err
+}
+
+// MassDelete 删除群发，articleIdx 为 0 时删除全部文章
+func (m *Mp) MassDelete(msgID int64, articleIdx int) error {
+	_, err := m.post("/cgi-bin/message/mass/delete", map[string]interface{}{
+		"msg_id":      msgID,
+		"article_idx": articleIdx,
+	})
+	return err
+}
+
+// MassGet 查询群发消息发送状态
+func (m *Mp) MassGet(msgID int64) (string, error) {
+	res, err := m.post("/cgi-bin/message/mass/get", map[string]interface{}{
+		"msg_id": msgID,
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.Get("msg_status").String(), nil
+}
+
+// MassGetSpeed 获取群发速度
+func (m *Mp) MassGetSpeed() (speed, realspeed int, err error) {
+	var res *zjson.Res
+	res, err = m.post("/cgi-bin/message/mass/speed/get", map[string]interface{}{})
+	if err != nil {
+		return
+	}
+	return res.Get("speed").Int(), res.Get("realspeed").Int(), nil
+}
+
+// MassSetSpeed 设置群发速度，取值 0 到 4，数值越小速度越快
+func (m *Mp) MassSetSpeed(speed int) error {
+	_, err := m.post("/cgi-bin/message/mass/speed/set", map[string]interface{}{
+		"speed": speed,
+	})
+	return err
+}
+
+// MassSendJob 解析群发结果事件
+func (t *ReplySt) MassSendJob() (result MassSendJobResult, err error) {
+	if t.Event != EventMassSendJobFinish {
+		return result, errors.New("not a " + EventMassSendJobFinish + " event")
+	}
+	err = t.Unmarshal(&result)
+	return
+}
@@ -0,0 +1,131 @@
+package wechat
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestMassChunkOpenIDs(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	openids := make([]string, 10)
+	for i := range openids {
+		openids[i] = strconv.Itoa(i)
+	}
+	for size, lens := range map[int][]int{
+		10: {10},
+		5:  {5, 5},
+		3:  {3, 3, 2, 2},
+		9:  {8, 2},
+	} {
+		chunks := chunkOpenIDs(openids, size)
+		l := make([]int, 0, len(chunks))
+		for i := range chunks {
+			l = append(l, len(chunks[i]))
+		}
+		tt.Equal(lens, l)
+	}
+}
+
+func TestMassSend(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	mp := &Mp{AppID: "wx_mass"}
+	e := New(mp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		if r.JSON("clientmsgid").String() == "dup" {
+			return `{"errcode":45065,"errmsg":"clientmsgid exist","msg_id":1}`
+		}
+		return `{"errcode":0,"errmsg":"send job submission success","msg_id":34182,"msg_data_id":206227730}`
+	})
+	defer api.Close()
+
+	result, err := mp.MassSendAll(2, MassImages{MediaIDs: []string{"m1", "m2"}, Recommend: "r"})
+	tt.Equal(true, err == nil)
+	tt.Equal(int64(34182), result.MsgID)
+	tt.Equal(int64(206227730), result.MsgDataID)
+	req := api.Last()
+	tt.Equal("/cgi-bin/message/mass/sendall", req.Path)
+	tt.Equal("image", req.JSON("msgtype").String())
+	tt.Equal("m2", req.JSON("images.media_ids.1").String())
+	tt.Equal("r", req.JSON("images.recommend").String())
+	tt.Equal(false, req.JSON("image").Exists())
+	tt.Equal(2, req.JSON("filter.tag_id").Int())
+	id := req.JSON("clientmsgid").String()
+	tt.Equal(32, len(id))
+	_, _ = mp.MassSendAll(2, MassImages{MediaIDs: []string{"m1", "m2"}, Recommend: "r"})
+	tt.Equal(id, api.Last().JSON("clientmsgid").String())
+	_, _ = mp.MassSendAll(3, MassImages{MediaIDs: []string{"m1", "m2"}, Recommend: "r"})
+	tt.Equal(false, id == api.Last().JSON("clientmsgid").String())
+
+	results, err := mp.MassSend([]string{"o1", "o2"}, MassText{Content: "hi"}, WithMassClientMsgID("id"))
+	tt.Equal(true, err == nil)
+	tt.Equal(1, len(results))
+	req = api.Last()
+	tt.Equal("/cgi-bin/message/mass/send", req.Path)
+	tt.Equal("text", req.JSON("msgtype").String())
+	tt.Equal("hi", req.JSON("text.content").String())
+	tt.Equal("o2", req.JSON("touser.1").String())
+	tt.Equal("id", req.JSON("clientmsgid").String())
+
+	_, err = mp.MassSendAll(0, MassText{Content: "hi"}, WithMassClientMsgID("dup"))
+	tt.Equal(45065, ErrorCode(err))
+}
+
+func TestMassSendRetry(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	mp := &Mp{AppID: "wx_mass_retry"}
+	e := New(mp)
+	sent := map[string]bool{}
+	api := newMockAPI(e, func(r mockRequest) string {
+		id := r.JSON("clientmsgid").String()
+		if sent[id] {
+			return `{"errcode":45065,"errmsg":"clientmsgid exist"}`
+		}
+		sent[id] = true
+		return `{"errcode":0,"errmsg":"send job submission success","msg_id":1}`
+	})
+	defer api.Close()
+
+	openids := make([]string, massMaxOpenID*2+2)
+	for i := range openids {
+		openids[i] = strconv.Itoa(i)
+	}
+	// 模拟首个分批已发送成功但响应超时
+	data, _ := massData(MassText{Content: "hi"}, nil)
+	data["touser"] = openids[:massMaxOpenID]
+	sent[massClientMsgID(data)] = true
+
+	results, err := mp.MassSend(openids, MassText{Content: "hi"})
+	tt.Equal(true, err == nil)
+	tt.Equal([]MassResult{{}, {MsgID: 1}, {MsgID: 1}}, results)
+	reqs := api.Requests()
+	tt.Equal(3, len(reqs))
+	tt.Equal(openids[len(openids)-1], reqs[2].JSON("touser.1").String())
+	tt.Equal(3, len(sent))
+
+	// 全部重试时不会重复发送
+	results, err = mp.MassSend(openids, MassText{Content: "hi"})
+	tt.Equal(true, err == nil)
+	tt.Equal(3, len(results))
+	tt.Equal(3, len(sent))
+
+	_, err = mp.MassSend(openids, MassText{Content: "hi"}, WithMassClientMsgID("id"))
+	tt.Equal(true, err == nil)
+	tt.Equal(true, sent["id_0"] && sent["id_1"] && sent["id_2"])
+}
+
+func TestMassSendJob(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	r := &ReceivedSt{bodyData: []byte(`<xml><ToUserName><![CDATA[gh_4d00ed8d6399]]></ToUserName><FromUserName><![CDATA[oV5CrjpxgaGXNHIQigzNlgLTnwic]]></FromUserName><CreateTime>1481013459</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[MASSSENDJOBFINISH]]></Event><MsgID>1000001625</MsgID><Status><![CDATA[err(30003)]]></Status><TotalCount>0</TotalCount><FilterCount>0</FilterCount><SentCount>0</SentCount><ErrorCount>0</ErrorCount><CopyrightCheckResult><Count>2</Count><ResultList><item><ArticleIdx>1</ArticleIdx><UserDeclareState>0</UserDeclareState><AuditState>2</AuditState><OriginalArticleUrl><![CDATA[Url_1]]></OriginalArticleUrl><OriginalArticleType>1</OriginalArticleType><CanReprint>1</CanReprint><NeedReplaceContent>1</NeedReplaceContent><NeedShowReprintSource>1</NeedShowReprintSource></item><item><ArticleIdx>2</ArticleIdx><UserDeclareState>0</UserDeclareState><AuditState>2</AuditState><OriginalArticleUrl><![CDATA[Url_2]]></OriginalArticleUrl><OriginalArticleType>1</OriginalArticleType><CanReprint>1</CanReprint><NeedReplaceContent>1</NeedReplaceContent><NeedShowReprintSource>1</NeedShowReprintSource></item></ResultList><CheckState>2</CheckState></CopyrightCheckResult><ArticleUrlResult><Count>1</Count><ResultList><item><ArticleIdx>1</ArticleIdx><ArticleUrl><![CDATA[https://mp.weixin.qq.com/]]></ArticleUrl></item></ResultList></ArticleUrlResult></xml>`)}
+	data, err := r.Data()
+	tt.Equal(true, err == nil)
+	result, err := data.MassSendJob()
+	tt.Equal(true, err == nil)
+	tt.Equal(int64(1000001625), result.MsgID)
+	tt.Equal("err(30003)", result.Status)
+	tt.Equal(2, len(result.CopyrightCheckResult.ResultList))
+	tt.Equal("Url_2", result.CopyrightCheckResult.ResultList[1].OriginalArticleURL)
+	tt.Equal(2, result.CopyrightCheckResult.CheckState)
+	tt.Equal("https://mp.weixin.qq.com/", result.ArticleURLResult.ResultList[0].ArticleURL)
+}
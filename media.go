@@ -0,0 +1,252 @@
+package wechat
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/sohaha/zlsgo/zhttp"
+	"github.com/sohaha/zlsgo/zjson"
+)
+
+type (
+	// MediaType 多媒体文件类型
+	MediaType string
+
+	// MediaUpload 临时素材上传结果
+	MediaUpload struct {
+		Type         string `json:"type"`
+		MediaID      string `json:"media_id"`
+		ThumbMediaID string `json:"thumb_media_id"`
+		CreatedAt    int64  `json:"created_at"`
+	}
+
+	// Material 永久素材上传结果
+	Material struct {
+		MediaID string `json:"media_id"`
+		URL     string `json:"url"`
+	}
+
+	// MaterialVideo 永久视频素材描述
+	MaterialVideo struct {
+		Title        string `json:"title"`
+		Introduction string `json:"introduction"`
+		DownURL      string `json:"down_url,omitempty"`
+	}
+
+	// MaterialCount 永久素材总数
+	MaterialCount struct {
+		VoiceCount int `json:"voice_count"`
+		VideoCount int `json:"video_count"`
+		ImageCount int `json:"image_count"`
+		NewsCount  int `json:"news_count"`
+	}
+
+	// MaterialItem 永久素材列表项
+	MaterialItem struct {
+		MediaID    string          `json:"media_id"`
+		Name       string          `json:"name"`
+		UpdateTime int64           `json:"update_time"`
+		URL        string          `json:"url"`
+		Content    json.RawMessage `json:"content,omitempty"`
+	}
+
+	// MaterialList 永久素材列表
+	MaterialList struct {
+		TotalCount int            `json:"total_count"`
+		ItemCount  int            `json:"item_count"`
+		Item       []MaterialItem `json:"item"`
+	}
+)
+
+const (
+	MediaTypeImage MediaType = "image"
+	MediaTypeVoice MediaType = "voice"
+	MediaTypeVideo MediaType = "video"
+	MediaTypeThumb MediaType = "thumb"
+	// MediaTypeNews 仅用于永久素材列表
+	MediaTypeNews MediaType = "news"
+)
+
+const (
+	mediaUploadImgLimit = 1 << 20
+)
+
+var mediaLimits = map[MediaType]struct {
+	size int64
+	code int
+	exts []string
+}{
+	MediaTypeImage: {10 << 20, 40009, []string{".bmp", ".png", ".jpeg", ".jpg", ".gif"}},
+	MediaTypeVoice: {2 << 20, 40010, []string{".mp3", ".wma", ".wav", ".amr"}},
+	MediaTypeVideo: {10 << 20, 40011, []string{".mp4"}},
+	MediaTypeThumb: {64 << 10, 40012, []string{".jpg", ".jpeg"}},
+}
+
+// readMedia 读取文件内容，超过大小限制时不再继续读取
+func readMedia(r io.Reader, limit int64, code int) ([]byte, error) {
+	if r == nil {
+		return nil, codeError(41005)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, codeError(44001)
+	}
+	if int64(len(data)) > limit {
+		return nil, codeError(code)
+	}
+	return data, nil
+}
+
+func checkMediaExt(fileName string, exts []string) error {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	for i := range exts {
+		if exts[i] == ext {
+			return nil
+		}
+	}
+	return codeError(40005)
+}
+
+func checkMedia(t MediaType, fileName string, r io.Reader) ([]byte, error) {
+	limit, ok := mediaLimits[t]
+	if !ok {
+		return nil, codeError(40004)
+	}
+	if err := checkMediaExt(fileName, limit.exts); err != nil {
+		return nil, err
+	}
+	return readMedia(r, limit.size, limit.code)
+}
+
+// UploadMedia 上传临时素材
+func (e *Engine) UploadMedia(t MediaType, fileName string, r io.Reader) (media MediaUpload, err error) {
+	var data []byte
+	data, err = checkMedia(t, fileName, r)
+	if err != nil {
+		return
+	}
+	var res *zjson.Res
+	res, err = e.httpAccessTokenUpload(e.apiURL+"/cgi-bin/media/upload", "media", fileName, data,
+		zhttp.QueryParam{"type": string(t)})
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &media)
+	if err == nil && media.MediaID == "" {
+		media.MediaID = media.ThumbMediaID
+	}
+	return
+}
+
+// GetMedia 获取临时素材
+func (e *Engine) GetMedia(mediaID string) ([]byte, error) {
+	return e.HttpAccessTokenGetRaw(e.apiURL+"/cgi-bin/media/get", zhttp.QueryParam{"media_id": mediaID})
+}
+
+// GetJssdkMedia 获取 JSSDK 上传的高清语音素材
+func (e *Engine) GetJssdkMedia(mediaID string) ([]byte, error) {
+	return e.HttpAccessTokenGetRaw(e.apiURL+"/cgi-bin/media/get/jssdk", zhttp.QueryParam{"media_id": mediaID})
+}
+
+// UploadImg 上传图文消息内的图片，返回图片 URL
+func (e *Engine) UploadImg(fileName string, r io.Reader) (string, error) {
+	if err := checkMediaExt(fileName, []string{".jpg", ".jpeg", ".png"}); err != nil {
+		return "", err
+	}
+	data, err := readMedia(r, mediaUploadImgLimit, 45001)
+	if err != nil {
+		return "", err
+	}
+	res, err := e.httpAccessTokenUpload(e.apiURL+"/cgi-bin/media/uploadimg", "media", fileName, data)
+	if err != nil {
+		return "", err
+	}
+	return res.Get("url").String(), nil
+}
+
+// AddMaterial 新增永久素材，视频素材需要通过 AddMaterialVideo 上传
+func (e *Engine) AddMaterial(t MediaType, fileName string, r io.Reader) (material Material, err error) {
+	if t == MediaTypeVideo {
+		return e.AddMaterialVideo(fileName, r, MaterialVideo{})
+	}
+	return e.addMaterial(t, fileName, r)
+}
+
+// AddMaterialVideo 新增永久视频素材
+func (e *Engine) AddMaterialVideo(fileName string, r io.Reader, desc MaterialVideo) (material Material, err error) {
+	description, _ := json.Marshal(desc)
+	return e.addMaterial(MediaTypeVideo, fileName, r, zhttp.Param{"description": string(description)})
+}
+
+func (e *Engine) addMaterial(t MediaType, fileName string, r io.Reader, v ...interface{}) (material Material, err error) {
+	var data []byte
+	data, err = checkMedia(t, fileName, r)
+	if err != nil {
+		return
+	}
+	var res *zjson.Res
+	res, err = e.httpAccessTokenUpload(e.apiURL+"/cgi-bin/material/add_material", "media", fileName, data,
+		append(v, zhttp.QueryParam{"type": string(t)})...)
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &material)
+	return
+}
+
+// GetMaterial 获取永久素材，图文与视频素材返回 JSON 内容
+func (e *Engine) GetMaterial(mediaID string) ([]byte, error) {
+	return e.HttpAccessTokenPostRaw(e.apiURL+"/cgi-bin/material/get_material", map[string]string{"media_id": mediaID})
+}
+
+// GetMaterialVideo 获取永久视频素材
+func (e *Engine) GetMaterialVideo(mediaID string) (video MaterialVideo, err error) {
+	var res *zjson.Res
+	res, err = e.HttpAccessTokenPost(e.apiURL+"/cgi-bin/material/get_material", map[string]string{"media_id": mediaID})
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &video)
+	return
+}
+
+// DelMaterial 删除永久素材
+func (e *Engine) DelMaterial(mediaID string) error {
+	_, err := e.HttpAccessTokenPost(e.apiURL+"/cgi-bin/material/del_material", map[string]string{"media_id": mediaID})
+	return err
+}
+
+// GetMaterialCount 获取永久素材总数
+func (e *Engine) GetMaterialCount() (count MaterialCount, err error) {
+	var res *zjson.Res
+	res, err = e.HttpAccessTokenGet(e.apiURL + "/cgi-bin/material/get_materialcount")
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &count)
+	return
+}
+
+// BatchGetMaterial 获取永久素材列表，count 取值 1 到 20
+func (e *Engine) BatchGetMaterial(t MediaType, offset, count int) (list MaterialList, err error) {
+	if count < 1 || count > 20 {
+		count = 20
+	}
+	var res *zjson.Res
+	res, err = e.HttpAccessTokenPost(e.apiURL+"/cgi-bin/material/batchget_material", map[string]interface{}{
+		"type":   t,
+		"offset": offset,
+		"count":  count,
+	})
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &list)
+	return
+}
@@ -0,0 +1,80 @@
+package wechat
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestMediaCheck(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	data, err := checkMedia(MediaTypeImage, "a.PNG", strings.NewReader("png"))
+	tt.Equal(true, err == nil)
+	tt.Equal("png", string(data))
+
+	_, err = checkMedia(MediaType("file"), "a.png", strings.NewReader("png"))
+	tt.Equal(40004, ErrorCode(err))
+
+	_, err = checkMedia(MediaTypeVideo, "a.avi", strings.NewReader("avi"))
+	tt.Equal(40005, ErrorCode(err))
+
+	_, err = checkMedia(MediaTypeVoice, "a.mp3", strings.NewReader(""))
+	tt.Equal(44001, ErrorCode(err))
+
+	_, err = checkMedia(MediaTypeThumb, "a.jpg", bytes.NewReader(make([]byte, 64<<10+1)))
+	tt.Equal(40012, ErrorCode(err))
+
+	_, err = checkMedia(MediaTypeThumb, "a.jpg", bytes.NewReader(make([]byte, 64<<10)))
+	tt.Equal(true, err == nil)
+}
+
+func TestIsBinaryContentType(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	for ctype, binary := range map[string]bool{
+		"":                                false,
+		"application/json; charset=utf-8": false,
+		"text/plain":                      false,
+		"text/xml":                        false,
+		"image/jpeg":                      true,
+		"audio/amr":                       true,
+		"application/octet-stream":        true,
+	} {
+		tt.Equal(binary, isBinaryContentType(ctype))
+	}
+}
+
+func TestGetMediaTokenExpired(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	e := New(&Mp{AppID: "wx_media_expired", AppSecret: "secret"})
+	api := newMockAPI(e, func(r mockRequest) string {
+		switch {
+		case r.Path == "/cgi-bin/token":
+			return `{"access_token":"NEW_TOKEN","expires_in":7200}`
+		case r.Query.Get("access_token") == "ACCESS_TOKEN":
+			return `{"errcode":42001,"errmsg":"access_token expired"}`
+		}
+		return "media"
+	})
+	defer api.Close()
+
+	b, err := e.GetMedia("media_id")
+	tt.Equal(true, err == nil)
+	tt.Equal("media", string(b))
+	reqs := api.Requests()
+	tt.Equal(3, len(reqs))
+	tt.Equal("/cgi-bin/token", reqs[1].Path)
+	tt.Equal("secret", reqs[1].Query.Get("secret"))
+	tt.Equal("/cgi-bin/media/get", reqs[2].Path)
+	tt.Equal("NEW_TOKEN", reqs[2].Query.Get("access_token"))
+	tt.Equal("media_id", reqs[2].Query.Get("media_id"))
+
+	_ = e.SetAccessToken("ACCESS_TOKEN", 7200)
+	b, err = e.GetMaterial("media_id")
+	tt.Equal(true, err == nil)
+	tt.Equal("media", string(b))
+	tt.Equal("NEW_TOKEN", api.Last().Query.Get("access_token"))
+	tt.Equal("media_id", api.Last().JSON("media_id").String())
+}
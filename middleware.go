@@ -0,0 +1,324 @@
+package wechat
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	netHttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/sohaha/zlsgo/zcache"
+	"github.com/sohaha/zlsgo/zstring"
+)
+
+type (
+	// OAuthSession 已授权用户身份
+	OAuthSession struct {
+		OpenID    string    `json:"openid"`
+		UnionID   string    `json:"unionid,omitempty"`
+		Scope     string    `json:"scope,omitempty"`
+		Snapshot  bool      `json:"snapshot,omitempty"`
+		UserInfo  *UserInfo `json:"userinfo,omitempty"`
+		ExpiresAt int64     `json:"expires_at"`
+	}
+
+	// SessionStore 授权会话存储
+	SessionStore interface {
+		Get(r *netHttp.Request) (*OAuthSession, error)
+		Save(w netHttp.ResponseWriter, r *netHttp.Request, session *OAuthSession) error
+		Delete(w netHttp.ResponseWriter, r *netHttp.Request) error
+	}
+
+	// OAuthMiddlewareOption 网页授权中间件配置
+	OAuthMiddlewareOption struct {
+		Scope ScopeType
+		// Store 会话存储，默认使用服务端缓存存储
+		Store SessionStore
+		// Lang 获取用户信息的语言
+		Lang string
+		// AllowSnapshot 是否放行朋友圈快照页的虚拟用户，放行时会话不会被保存
+		AllowSnapshot bool
+		// ErrorHandler 授权失败时的处理，默认返回 403
+		ErrorHandler func(w netHttp.ResponseWriter, r *netHttp.Request, err error)
+		// TrustRequestHost 未设置 WithRedirectDomain 时使用请求的 Host 与 X-Forwarded-Proto 生成回调地址，
+		// 仅应在可信的反向代理之后开启
+		TrustRequestHost bool
+	}
+
+	cookieSessionStore struct {
+		name   string
+		secret []byte
+		maxAge time.Duration
+	}
+
+	cacheSessionStore struct {
+		name   string
+		maxAge time.Duration
+		cache  *zcache.Table
+	}
+
+	oauthSessionKey struct{}
+)
+
+const (
+	defaultSessionName   = "wechat_session"
+	defaultSessionMaxAge = 2 * time.Hour
+	sessionIDLen         = 32
+)
+
+var (
+	ErrSessionNotFound   = errors.New("oauth session not found")
+	ErrOAuthSnapshotUser = errors.New("snapshot user needs to open the full page")
+	ErrSessionSecret     = errors.New("session secret must not be empty")
+	ErrRedirectDomain    = errors.New("redirect domain is not set, use WithRedirectDomain")
+)
+
+// NewCookieSessionStore 使用 HMAC 签名的 Cookie 保存会话，Cookie 内容未加密，不会保存任何 token
+func NewCookieSessionStore(name string, secret []byte, maxAge time.Duration) (SessionStore, error) {
+	if len(secret) == 0 {
+		return nil, ErrSessionSecret
+	}
+	if name == "" {
+		name = defaultSessionName
+	}
+	if maxAge <= 0 {
+		maxAge = defaultSessionMaxAge
+	}
+	return &cookieSessionStore{name: name, secret: secret, maxAge: maxAge}, nil
+}
+
+func (s *cookieSessionStore) sign(data string) string {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write(zstring.String2Bytes(data))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+func (s *cookieSessionStore) Get(r *netHttp.Request) (*OAuthSession, error) {
+	cookie, err := r.Cookie(s.name)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+	i := strings.LastIndex(cookie.Value, ".")
+	if i <= 0 {
+		return nil, ErrSessionNotFound
+	}
+	data, sign := cookie.Value[:i], cookie.Value[i+1:]
+	if !hmac.Equal(zstring.String2Bytes(sign), zstring.String2Bytes(s.sign(data))) {
+		return nil, ErrSessionNotFound
+	}
+	b, err := base64.RawURLEncoding.DecodeString(data)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+	return decodeSession(b)
+}
+
+func (s *cookieSessionStore) Save(w netHttp.ResponseWriter, r *netHttp.Request, session *OAuthSession) error {
+	session.ExpiresAt = time.Now().Add(s.maxAge).Unix()
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	data := base64.RawURLEncoding.EncodeToString(b)
+	setSessionCookie(w, r, s.name, data+"."+s.sign(data), s.maxAge)
+	return nil
+}
+
+func (s *cookieSessionStore) Delete(w netHttp.ResponseWriter, r *netHttp.Request) error {
+	setSessionCookie(w, r, s.name, "", -1)
+	return nil
+}
+
+// NewCacheSessionStore 会话保存在服务端缓存中，Cookie 仅保存随机会话 ID，默认 Cookie 名称包含 AppID
+func (e *Engine) NewCacheSessionStore(name string, maxAge time.Duration) SessionStore {
+	appid := e.GetAppID()
+	if name == "" {
+		name = defaultSessionName + "_" + appid
+	}
+	if maxAge <= 0 {
+		maxAge = defaultSessionMaxAge
+	}
+	return &cacheSessionStore{name: name, maxAge: maxAge, cache: zcache.New(cachePrtfix + "session" + appid)}
+}
+
+func (s *cacheSessionStore) Get(r *netHttp.Request) (*OAuthSession, error) {
+	cookie, err := r.Cookie(s.name)
+	if err != nil || len(cookie.Value) != sessionIDLen {
+		return nil, ErrSessionNotFound
+	}
+	data, err := s.cache.GetString(cookie.Value)
+	if err != nil {
+		return nil, ErrSessionNotFound
+	}
+	return decodeSession(zstring.String2Bytes(data))
+}
+
+func (s *cacheSessionStore) Save(w netHttp.ResponseWriter, r *netHttp.Request, session *OAuthSession) error {
+	session.ExpiresAt = time.Now().Add(s.maxAge).Unix()
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	id := zstring.Rand(sessionIDLen)
+	s.cache.Set(id, zstring.Bytes2String(b), uint(s.maxAge/time.Second))
+	setSessionCookie(w, r, s.name, id, s.maxAge)
+	return nil
+}
+
+func (s *cacheSessionStore) Delete(w netHttp.ResponseWriter, r *netHttp.Request) error {
+	if cookie, err := r.Cookie(s.name); err == nil {
+		_, _ = s.cache.Delete(cookie.Value)
+	}
+	setSessionCookie(w, r, s.name, "", -1)
+	return nil
+}
+
+func decodeSession(b []byte) (*OAuthSession, error) {
+	session := &OAuthSession{}
+	if err := json.Unmarshal(b, session); err != nil {
+		return nil, ErrSessionNotFound
+	}
+	if session.OpenID == "" || session.ExpiresAt < time.Now().Unix() {
+		return nil, ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func setSessionCookie(w netHttp.ResponseWriter, r *netHttp.Request, name, value string, maxAge time.Duration) {
+	cookie := &netHttp.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   requestScheme(r) == "https",
+		SameSite: netHttp.SameSiteLaxMode,
+		MaxAge:   int(maxAge / time.Second),
+	}
+	if maxAge < 0 {
+		cookie.MaxAge = -1
+	}
+	netHttp.SetCookie(w, cookie)
+}
+
+func requestScheme(r *netHttp.Request) string {
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		return strings.ToLower(strings.TrimSpace(strings.Split(proto, ",")[0]))
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// OAuthSessionFromContext 获取中间件写入的授权用户
+func OAuthSessionFromContext(ctx context.Context) (*OAuthSession, bool) {
+	session, ok := ctx.Value(oauthSessionKey{}).(*OAuthSession)
+	return session, ok
+}
+
+// OAuthMiddleware net/http 网页授权中间件，未授权时跳转授权页，授权完成后保存会话并写入 context
+func (e *Engine) OAuthMiddleware(opt OAuthMiddlewareOption) func(netHttp.Handler) netHttp.Handler {
+	if opt.Store == nil {
+		opt.Store = e.NewCacheSessionStore("", 0)
+	}
+	if opt.ErrorHandler == nil {
+		opt.ErrorHandler = func(w netHttp.ResponseWriter, r *netHttp.Request, err error) {
+			netHttp.Error(w, ErrorMsg(err), netHttp.StatusForbidden)
+		}
+	}
+	return func(next netHttp.Handler) netHttp.Handler {
+		return netHttp.HandlerFunc(func(w netHttp.ResponseWriter, r *netHttp.Request) {
+			if session, err := opt.Store.Get(r); err == nil {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), oauthSessionKey{}, session)))
+				return
+			}
+
+			uri, err := e.requestURL(r, opt.TrustRequestHost)
+			if err != nil {
+				opt.ErrorHandler(w, r, err)
+				return
+			}
+			query := r.URL.Query()
+			code := query.Get("code")
+			if code == "" {
+				if len(e.redirectHosts()) > 0 && !e.allowRedirect(uri, false) {
+					opt.ErrorHandler(w, r, ErrAuthRedirectHost)
+					return
+				}
+				authState, err := e.NewAuthState("", uri)
+				if err != nil {
+					opt.ErrorHandler(w, r, err)
+					return
+				}
+				netHttp.Redirect(w, r, e.getOauthRedirect(uri, authState, opt.Scope), netHttp.StatusFound)
+				return
+			}
+
+			bound, err := e.VerifyAuthState(query.Get("state"))
+			if err != nil {
+				opt.ErrorHandler(w, r, ErrAuthState)
+				return
+			}
+			session, err := e.oauthSession(code, opt)
+			if err != nil {
+				opt.ErrorHandler(w, r, err)
+				return
+			}
+			if session.Snapshot {
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), oauthSessionKey{}, session)))
+				return
+			}
+			if err = opt.Store.Save(w, r, session); err != nil {
+				opt.ErrorHandler(w, r, err)
+				return
+			}
+			netHttp.Redirect(w, r, bound.ReturnTo, netHttp.StatusFound)
+		})
+	}
+}
+
+func (e *Engine) oauthSession(code string, opt OAuthMiddlewareOption) (*OAuthSession, error) {
+	token, err := e.GetOAuthToken(code)
+	if err != nil {
+		return nil, err
+	}
+	session := &OAuthSession{
+		OpenID:   token.OpenID,
+		UnionID:  token.UnionID,
+		Scope:    token.Scope,
+		Snapshot: token.IsSnapshot(),
+	}
+	if session.Snapshot {
+		// 快照页用户的 openid 为虚拟值，不能作为真实用户保存
+		if !opt.AllowSnapshot {
+			return nil, ErrOAuthSnapshotUser
+		}
+		return session, nil
+	}
+	if opt.Scope == ScopeUserinfo || opt.Scope == ScopeLogin || (opt.Scope == "" && strings.Contains(token.Scope, string(ScopeUserinfo))) {
+		info, err := e.GetOAuthUserInfo(token.OpenID, token.AccessToken, opt.Lang)
+		if err != nil {
+			return nil, err
+		}
+		session.UserInfo = &info
+		if session.UnionID == "" {
+			session.UnionID = info.UnionID
+		}
+	}
+	return session, nil
+}
+
+// requestURL 当前请求的完整地址，已去除 code、state 参数，默认不信任请求头中的域名
+func (e *Engine) requestURL(r *netHttp.Request, trustHost bool) (string, error) {
+	if len(e.redirectDomain) > 0 {
+		return paramFilter(e.redirectDomain + r.URL.RequestURI()), nil
+	}
+	if !trustHost {
+		return "", ErrRedirectDomain
+	}
+	return paramFilter(requestScheme(r) + "://" + r.Host + r.URL.RequestURI()), nil
+}
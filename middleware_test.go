@@ -0,0 +1,134 @@
+package wechat
+
+import (
+	netHttp "net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestCookieSessionStore(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	_, err := NewCookieSessionStore("", nil, time.Hour)
+	tt.Equal(ErrSessionSecret, err)
+	store, err := NewCookieSessionStore("", []byte("secret"), time.Hour)
+	tt.Equal(true, err == nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "https://example.com/", nil)
+	err = store.Save(w, r, &OAuthSession{OpenID: "openid", Scope: string(ScopeBase)})
+	tt.Equal(true, err == nil)
+	cookie := w.Result().Cookies()[0]
+	tt.Equal(true, cookie.Secure)
+
+	r = httptest.NewRequest("GET", "https://example.com/", nil)
+	r.AddCookie(cookie)
+	session, err := store.Get(r)
+	tt.Equal(true, err == nil)
+	tt.Equal("openid", session.OpenID)
+
+	r = httptest.NewRequest("GET", "https://example.com/", nil)
+	r.AddCookie(&netHttp.Cookie{Name: cookie.Name, Value: cookie.Value + "x"})
+	_, err = store.Get(r)
+	tt.Equal(ErrSessionNotFound, err)
+}
+
+func TestOAuthMiddleware(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	wx := New(&Mp{AppID: "wx_middleware"})
+	next := netHttp.HandlerFunc(func(w netHttp.ResponseWriter, r *netHttp.Request) {
+		session, _ := OAuthSessionFromContext(r.Context())
+		_, _ = w.Write([]byte(session.OpenID))
+	})
+	handler := wx.OAuthMiddleware(OAuthMiddlewareOption{Scope: ScopeBase})(next)
+
+	// 未设置回调域名时不信任请求头
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "http://example.com/user?id=1", nil)
+	r.Host = "evil.com"
+	handler.ServeHTTP(w, r)
+	tt.Equal(netHttp.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	wx.OAuthMiddleware(OAuthMiddlewareOption{Scope: ScopeBase, TrustRequestHost: true})(next).
+		ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/user?id=1", nil))
+	tt.Equal(netHttp.StatusFound, w.Code)
+	tt.Equal(true, strings.Contains(w.Header().Get("Location"), "redirect_uri=http%3A%2F%2Fexample.com%2Fuser%3Fid%3D1"))
+
+	wx.SetOptions(WithRedirectDomain("https://example.com"))
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "http://example.com/user?id=1", nil)
+	r.Host = "evil.com"
+	r.Header.Set("X-Forwarded-Proto", "http")
+	handler.ServeHTTP(w, r)
+	tt.Equal(netHttp.StatusFound, w.Code)
+	location := w.Header().Get("Location")
+	tt.Equal(true, strings.Contains(location, "scope=snsapi_base"))
+	tt.Equal(true, strings.Contains(location, "redirect_uri=https%3A%2F%2Fexample.com%2Fuser%3Fid%3D1"))
+
+	// 伪造或已使用的 state
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/user?id=1&code=c&state=forged", nil))
+	tt.Equal(netHttp.StatusForbidden, w.Code)
+	tt.Equal("", w.Header().Get("Location"))
+
+	// 回调地址不在允许的域名内
+	wx.SetOptions(WithRedirectDomain("https://evil.com"), WithAuthRedirectHosts("example.com"))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "http://example.com/user", nil))
+	tt.Equal(netHttp.StatusForbidden, w.Code)
+}
+
+func TestOAuthMiddlewareCallback(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	wx := New(&Mp{AppID: "wx_middleware_callback"})
+	wx.SetOptions(WithRedirectDomain("https://example.com"))
+	api := newMockAPI(wx, func(r mockRequest) string {
+		return `{"access_token":"oauth_token","expires_in":7200,"openid":"openid","scope":"snsapi_base"}`
+	})
+	defer api.Close()
+	handler := wx.OAuthMiddleware(OAuthMiddlewareOption{Scope: ScopeBase})(netHttp.HandlerFunc(
+		func(w netHttp.ResponseWriter, r *netHttp.Request) {
+			session, _ := OAuthSessionFromContext(r.Context())
+			_, _ = w.Write([]byte(session.OpenID))
+		}))
+
+	authState, _ := wx.NewAuthState("", "https://example.com/user?id=1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "https://example.com/user?id=1&code=c&state="+authState, nil))
+	tt.Equal(netHttp.StatusFound, w.Code)
+	tt.Equal("https://example.com/user?id=1", w.Header().Get("Location"))
+	tt.Equal("c", api.Last().Query.Get("code"))
+
+	r := httptest.NewRequest("GET", "https://example.com/user?id=1", nil)
+	r.AddCookie(w.Result().Cookies()[0])
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	tt.Equal(netHttp.StatusOK, w.Code)
+	tt.Equal("openid", w.Body.String())
+}
+
+func TestCacheSessionStore(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	a := New(&Mp{AppID: "wx_session_a"}).NewCacheSessionStore("", time.Hour)
+	b := New(&Mp{AppID: "wx_session_b"}).NewCacheSessionStore("", time.Hour)
+
+	w := httptest.NewRecorder()
+	tt.Equal(true, a.Save(w, httptest.NewRequest("GET", "/", nil), &OAuthSession{OpenID: "openid"}) == nil)
+	cookie := w.Result().Cookies()[0]
+	tt.Equal("wechat_session_wx_session_a", cookie.Name)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(cookie)
+	session, err := a.Get(r)
+	tt.Equal(true, err == nil)
+	tt.Equal("openid", session.OpenID)
+
+	r = httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&netHttp.Cookie{Name: "wechat_session_wx_session_b", Value: cookie.Value})
+	_, err = b.Get(r)
+	tt.Equal(ErrSessionNotFound, err)
+}
@@ -0,0 +1,72 @@
+package wechat
+
+import (
+	"io"
+	"io/ioutil"
+	netHttp "net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"github.com/sohaha/zlsgo/zjson"
+)
+
+type (
+	// mockRequest 模拟接口收到的请求
+	mockRequest struct {
+		Method string
+		Path   string
+		Query  url.Values
+		Header netHttp.Header
+		Body   []byte
+	}
+
+	// mockAPI 模拟微信接口，响应为空时返回 {"errcode":0,"errmsg":"ok"}
+	mockAPI struct {
+		*httptest.Server
+		mu       sync.Mutex
+		requests []mockRequest
+	}
+)
+
+func (r mockRequest) JSON(path string) *zjson.Res {
+	return zjson.ParseBytes(r.Body).Get(path)
+}
+
+// newMockAPI 将 e 的接口地址指向本地服务，并预置 access_token
+func newMockAPI(e *Engine, response func(r mockRequest) string) *mockAPI {
+	m := &mockAPI{}
+	m.Server = httptest.NewServer(netHttp.HandlerFunc(func(w netHttp.ResponseWriter, r *netHttp.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		req := mockRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query(), Header: r.Header, Body: body}
+		m.mu.Lock()
+		m.requests = append(m.requests, req)
+		m.mu.Unlock()
+		res := ""
+		if response != nil {
+			res = response(req)
+		}
+		if res == "" {
+			res = `{"errcode":0,"errmsg":"ok"}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, res)
+	}))
+	e.apiURL = m.URL
+	_ = e.SetAccessToken("ACCESS_TOKEN", 7200)
+	return m
+}
+
+func (m *mockAPI) Requests() []mockRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]mockRequest(nil), m.requests...)
+}
+
+func (m *mockAPI) Last() mockRequest {
+	reqs := m.Requests()
+	if len(reqs) == 0 {
+		return mockRequest{}
+	}
+	return reqs[len(reqs)-1]
+}
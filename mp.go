@@ -1,9 +1,11 @@
 package wechat
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/sohaha/zlsgo/zhttp"
+	"github.com/sohaha/zlsgo/zjson"
 )
 
 type (
@@ -28,6 +30,31 @@ func (m *Mp) getEngine() *Engine {
 	return m.engine
 }
 
+func (m *Mp) checkEngine() (*Engine, error) {
+	if m.engine == nil {
+		return nil, errors.New(`please use wechat.New(&wechat.Mp{})`)
+	}
+	return m.engine, nil
+}
+
+// post 调用需要 access_token 的 POST 接口，path 为接口路径
+func (m *Mp) post(path string, v ...interface{}) (*zjson.Res, error) {
+	e, err := m.checkEngine()
+	if err != nil {
+		return nil, err
+	}
+	return e.HttpAccessTokenPost(e.apiURL+path, v...)
+}
+
+// get 调用需要 access_token 的 GET 接口，path 为接口路径
+func (m *Mp) get(path string, v ...interface{}) (*zjson.Res, error) {
+	e, err := m.checkEngine()
+	if err != nil {
+		return nil, err
+	}
+	return e.HttpAccessTokenGet(e.apiURL+path, v...)
+}
+
 func (m *Mp) GetAppID() string {
 	return m.AppID
 }
@@ -47,7 +74,7 @@ func (m *Mp) GetEncodingAesKey() string {
 func (m *Mp) getAccessToken() (data []byte, err error) {
 	res, err := http.Post(fmt.Sprintf(
 		"%s/cgi-bin/token?grant_type=client_credential&appid=%s&secret=%s",
-		APIURL, m.AppID, m.AppSecret))
+		m.engine.apiURL, m.AppID, m.AppSecret))
 	if err != nil {
 		return
 	}
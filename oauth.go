@@ -0,0 +1,205 @@
+package wechat
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sohaha/zlsgo/zjson"
+	"github.com/sohaha/zlsgo/zstring"
+)
+
+type (
+	// OAuthToken 网页授权 access_token
+	OAuthToken struct {
+		AccessToken    string `json:"access_token"`
+		ExpiresIn      int    `json:"expires_in"`
+		RefreshToken   string `json:"refresh_token"`
+		OpenID         string `json:"openid"`
+		Scope          string `json:"scope"`
+		UnionID        string `json:"unionid"`
+		IsSnapshotUser int    `json:"is_snapshotuser"`
+	}
+
+	// UserInfo 网页授权用户信息
+	UserInfo struct {
+		OpenID     string   `json:"openid"`
+		Nickname   string   `json:"nickname"`
+		Sex        int      `json:"sex"`
+		Province   string   `json:"province"`
+		City       string   `json:"city"`
+		Country    string   `json:"country"`
+		HeadImgURL string   `json:"headimgurl"`
+		Privilege  []string `json:"privilege"`
+		UnionID    string   `json:"unionid"`
+	}
+
+	// AuthState 授权 state 绑定的数据
+	AuthState struct {
+		State    string `json:"state"`
+		ReturnTo string `json:"return_to"`
+	}
+)
+
+const (
+	LangZhCN = "zh_CN"
+	LangZhTW = "zh_TW"
+	LangEn   = "en"
+)
+
+const (
+	authStateLen        = 32
+	defaultAuthStateTTL = 10 * time.Minute
+)
+
+var (
+	ErrOAuthNotSupport  = errors.New("oauth not support")
+	ErrAuthState        = errors.New("invalid or expired oauth state")
+	ErrAuthRedirectHost = errors.New("oauth redirect host is not allowed")
+)
+
+// IsSnapshot 是否为快照页模式虚拟账号，此时仅能获取到虚拟的 openid
+func (t OAuthToken) IsSnapshot() bool {
+	return t.IsSnapshotUser == 1
+}
+
+func (e *Engine) checkOAuth() error {
+	if e.IsQy() || e.IsWeapp() {
+		return ErrOAuthNotSupport
+	}
+	return nil
+}
+
+// GetOAuthToken 通过 code 换取网页授权 access_token
+func (e *Engine) GetOAuthToken(code string) (token OAuthToken, err error) {
+	if err = e.checkOAuth(); err != nil {
+		return
+	}
+	var res *zjson.Res
+	res, err = e.GetAuthInfo(code)
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &token)
+	return
+}
+
+// RefreshOAuthToken 刷新网页授权 access_token，暂不支持开放平台代公众号刷新
+func (e *Engine) RefreshOAuthToken(refreshToken string) (token OAuthToken, err error) {
+	if err = e.checkOAuth(); err != nil {
+		return
+	}
+	if e.IsOpen() {
+		return token, ErrOAuthNotSupport
+	}
+	u := zstring.Buffer(6)
+	u.WriteString(e.apiURL)
+	u.WriteString("/sns/oauth2/refresh_token?appid=")
+	u.WriteString(e.GetAppID())
+	u.WriteString("&grant_type=refresh_token&refresh_token=")
+	u.WriteString(refreshToken)
+	var res *zjson.Res
+	res, err = httpResProcess(http.Get(u.String()))
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &token)
+	return
+}
+
+// ValidateOAuthToken 检验网页授权 access_token 是否有效
+func (e *Engine) ValidateOAuthToken(accessToken, openid string) error {
+	if err := e.checkOAuth(); err != nil {
+		return err
+	}
+	u := zstring.Buffer(5)
+	u.WriteString(e.apiURL)
+	u.WriteString("/sns/auth?access_token=")
+	u.WriteString(accessToken)
+	u.WriteString("&openid=")
+	u.WriteString(openid)
+	_, err := httpResProcess(http.Get(u.String()))
+	return err
+}
+
+// GetOAuthUserInfo 通过网页授权 access_token 获取用户信息，需要 snsapi_userinfo 授权
+func (e *Engine) GetOAuthUserInfo(openid, accessToken string, lang ...string) (info UserInfo, err error) {
+	if err = e.checkOAuth(); err != nil {
+		return
+	}
+	var res *zjson.Res
+	res, err = e.GetAuthUserInfo(openid, accessToken, lang...)
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &info)
+	return
+}
+
+// NewAuthState 生成一次性授权 state，state 与 returnTo 保存在缓存中直到校验或过期
+func (e *Engine) NewAuthState(state, returnTo string) (string, error) {
+	data, err := json.Marshal(AuthState{State: state, ReturnTo: returnTo})
+	if err != nil {
+		return "", err
+	}
+	ttl := e.authStateTTL
+	if ttl <= 0 {
+		ttl = defaultAuthStateTTL
+	}
+	nonce := zstring.Rand(authStateLen)
+	e.cache.Set(cacheAuthState+nonce, zstring.Bytes2String(data), uint(ttl/time.Second))
+	return nonce, nil
+}
+
+// VerifyAuthState 校验授权回调的 state，校验后立即失效
+func (e *Engine) VerifyAuthState(nonce string) (state AuthState, err error) {
+	if len(nonce) != authStateLen {
+		return state, ErrAuthState
+	}
+	key := cacheAuthState + nonce
+	var raw string
+	raw, err = e.cache.GetString(key)
+	if err != nil || raw == "" {
+		return state, ErrAuthState
+	}
+	if _, err = e.cache.Delete(key); err != nil {
+		return state, ErrAuthState
+	}
+	if json.Unmarshal(zstring.String2Bytes(raw), &state) != nil {
+		return state, ErrAuthState
+	}
+	return state, nil
+}
+
+// allowRedirect 校验跳转地址，relative 为 true 时允许站内相对地址
+func (e *Engine) allowRedirect(uri string, relative bool) bool {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		return relative && strings.HasPrefix(uri, "/") && !strings.HasPrefix(uri, "//") &&
+			!strings.HasPrefix(uri, "/\\")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	return matchDomain(u.Hostname(), e.redirectHosts())
+}
+
+// redirectHosts 允许的回调及跳转域名，未设置时默认为 WithRedirectDomain 的域名
+func (e *Engine) redirectHosts() []string {
+	if len(e.authRedirectHosts) > 0 {
+		return e.authRedirectHosts
+	}
+	if e.redirectDomain == "" {
+		return nil
+	}
+	u, err := url.Parse(e.redirectDomain)
+	if err != nil || u.Hostname() == "" {
+		return nil
+	}
+	return []string{u.Hostname()}
+}
@@ -0,0 +1,179 @@
+package wechat
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/znet"
+)
+
+func TestAuthState(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	wx := New(&Mp{AppID: "wx_auth_state"})
+
+	nonce, err := wx.NewAuthState("login", "/user")
+	tt.Equal(true, err == nil)
+	tt.Equal(authStateLen, len(nonce))
+
+	state, err := wx.VerifyAuthState(nonce)
+	tt.Equal(true, err == nil)
+	tt.Equal(AuthState{State: "login", ReturnTo: "/user"}, state)
+
+	_, err = wx.VerifyAuthState(nonce)
+	tt.Equal(ErrAuthState, err)
+
+	_, err = wx.VerifyAuthState("forged")
+	tt.Equal(ErrAuthState, err)
+}
+
+func TestAuthCallbackState(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	wx := New(&Mp{AppID: "wx_auth_callback"})
+	api := newMockAPI(wx, nil)
+	defer api.Close()
+	callback := func(query string) *znet.Context {
+		return &znet.Context{Request: httptest.NewRequest("GET", "http://example.com/callback?"+query, nil)}
+	}
+
+	_, ok, err := wx.Auth(callback("code=c&state=forged"), "login", ScopeBase)
+	tt.Equal(false, ok)
+	tt.Equal(ErrAuthState, err)
+
+	nonce, _ := wx.NewAuthState("other", "")
+	_, ok, err = wx.Auth(callback("code=c&state="+nonce), "login", ScopeBase)
+	tt.Equal(false, ok)
+	tt.Equal(ErrAuthState, err)
+
+	// state 已使用
+	_, ok, err = wx.Auth(callback("code=c&state="+nonce), "other", ScopeBase)
+	tt.Equal(false, ok)
+	tt.Equal(ErrAuthState, err)
+	tt.Equal(0, len(api.Requests()))
+}
+
+func TestOAuthToken(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	wx := New(&Mp{AppID: "wx_oauth_token", AppSecret: "secret"})
+	api := newMockAPI(wx, func(r mockRequest) string {
+		switch r.Path {
+		case "/sns/oauth2/access_token":
+			if r.Query.Get("code") == "used" {
+				return `{"errcode":40163,"errmsg":"code been used"}`
+			}
+			return `{"access_token":"oauth_token","expires_in":7200,"refresh_token":"refresh","openid":"openid","scope":"snsapi_userinfo","is_snapshotuser":1}`
+		case "/sns/oauth2/refresh_token":
+			return `{"access_token":"new_token","expires_in":7200,"refresh_token":"refresh","openid":"openid","scope":"snsapi_base"}`
+		case "/sns/userinfo":
+			return `{"openid":"openid","nickname":"nick","unionid":"unionid"}`
+		case "/sns/auth":
+			return ""
+		}
+		return `{"errcode":40001,"errmsg":"invalid"}`
+	})
+	defer api.Close()
+
+	token, err := wx.GetOAuthToken("code")
+	tt.Equal(true, err == nil)
+	tt.Equal("oauth_token", token.AccessToken)
+	tt.Equal("openid", token.OpenID)
+	tt.Equal(true, token.IsSnapshot())
+	req := api.Last()
+	tt.Equal("wx_oauth_token", req.Query.Get("appid"))
+	tt.Equal("secret", req.Query.Get("secret"))
+	tt.Equal("code", req.Query.Get("code"))
+	tt.Equal("authorization_code", req.Query.Get("grant_type"))
+
+	_, err = wx.GetOAuthToken("used")
+	tt.Equal(40163, ErrorCode(err))
+
+	token, err = wx.RefreshOAuthToken("refresh")
+	tt.Equal(true, err == nil)
+	tt.Equal("new_token", token.AccessToken)
+	req = api.Last()
+	tt.Equal("refresh_token", req.Query.Get("grant_type"))
+	tt.Equal("refresh", req.Query.Get("refresh_token"))
+
+	tt.Equal(true, wx.ValidateOAuthToken("oauth_token", "openid") == nil)
+	req = api.Last()
+	tt.Equal("/sns/auth", req.Path)
+	tt.Equal("oauth_token", req.Query.Get("access_token"))
+
+	info, err := wx.GetOAuthUserInfo("openid", "oauth_token", "en")
+	tt.Equal(true, err == nil)
+	tt.Equal("nick", info.Nickname)
+	tt.Equal("unionid", info.UnionID)
+	tt.Equal("en", api.Last().Query.Get("lang"))
+
+	_, err = New(&Weapp{AppID: "wx_oauth_token_weapp"}).GetOAuthToken("code")
+	tt.Equal(ErrOAuthNotSupport, err)
+
+	// 开放平台需使用 component 接口刷新
+	open := New(&Open{AppID: "wx_oauth_token_open"})
+	open.apiURL = api.URL
+	n := len(api.Requests())
+	_, err = open.RefreshOAuthToken("refresh")
+	tt.Equal(ErrOAuthNotSupport, err)
+	tt.Equal(n, len(api.Requests()))
+}
+
+func TestAuthAllowRedirect(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	wx := New(&Mp{AppID: "wx_auth_redirect"})
+	wx.SetOptions(WithAuthRedirectHosts("example.com", "*.example.net"))
+	for uri, allow := range map[string]bool{
+		"/user?id=1":                true,
+		"//evil.com/":               false,
+		"/\\evil.com/":              false,
+		"https://example.com/a":     true,
+		"https://m.example.net/a":   true,
+		"https://example.com.evil/": false,
+		"javascript:alert(1)":       false,
+	} {
+		tt.Equal(allow, wx.allowRedirect(uri, true))
+	}
+	tt.Equal(false, wx.allowRedirect("/user", false))
+
+	// 未设置允许的域名时默认为回调域名
+	wx = New(&Mp{AppID: "wx_auth_redirect_domain"})
+	tt.Equal(0, len(wx.redirectHosts()))
+	wx.SetOptions(WithRedirectDomain("https://example.com:8443/"))
+	tt.Equal([]string{"example.com"}, wx.redirectHosts())
+	tt.Equal(true, wx.allowRedirect("https://example.com:8443/callback", false))
+	tt.Equal(false, wx.allowRedirect("https://evil.com/callback", false))
+}
+
+func TestQrConnect(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	mp := New(&Mp{AppID: "wx_qrconnect_mp"})
+	_, err := mp.GetQrConnectURL("https://example.com/callback", "", "")
+	tt.Equal(ErrOAuthNotSupport, err)
+
+	open := New(&Open{AppID: "wx_qrconnect_open"})
+	_, err = open.GetQrConnectURL("https://example.com/callback", "", "")
+	tt.Equal(ErrOAuthNotSupport, err)
+	tt.Equal(true, strings.HasPrefix(open.getOauthRedirect("https://example.com/callback", "s", ScopeBase), "https://open.weixin.qq.com/connect/oauth2/authorize?"))
+
+	wx := New(&Website{AppID: "wx_qrconnect"})
+	wx.SetOptions(WithAuthRedirectHosts("example.com"))
+	_, err = wx.GetQrConnectURL("https://evil.com/callback", "", "")
+	tt.Equal(ErrAuthRedirectHost, err)
+	_, err = wx.GetQrConnectURL("https://example.com/callback", "", "https://evil.com/")
+	tt.Equal(ErrAuthRedirectHost, err)
+	u, err := wx.GetQrConnectURL("https://example.com/callback", "", "")
+	tt.Equal(true, err == nil)
+	tt.Equal(true, strings.HasPrefix(u, "https://open.weixin.qq.com/connect/qrconnect?appid=wx_qrconnect&"))
+	tt.Equal(true, strings.Contains(u, "&scope=snsapi_login&"))
+
+	conf, err := wx.GetWxLoginConfig("login_container", "https://example.com/callback?a=1", "", "/user")
+	tt.Equal(true, err == nil)
+	tt.Equal("snsapi_login", conf.Scope)
+	tt.Equal("https%3A%2F%2Fexample.com%2Fcallback%3Fa%3D1", conf.RedirectURI)
+	bound, err := wx.VerifyAuthState(conf.State)
+	tt.Equal(true, err == nil)
+	tt.Equal("/user", bound.ReturnTo)
+
+	_, err = wx.LoginWebsite("code", conf.State, "")
+	tt.Equal(ErrAuthState, err)
+}
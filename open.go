@@ -167,16 +167,17 @@ func (e *Engine) ComponentVerifyTicket(raw string) (
 		return "", errors.New("illegal data")
 	}
 
-	var (
-		err        error
-		cipherText []byte
-	)
-	cipherText, _ = aesDecrypt(encrypt, config.EncodingAesKey)
-	appidOffset := len(cipherText) - len(zstring.String2Bytes(config.AppID))
-	if appid := string(cipherText[appidOffset:]); appid != config.AppID {
+	plaintext, err := aesDecrypt(encrypt, config.EncodingAesKey)
+	if err != nil {
+		return "", err
+	}
+	_, _, cipherText, appid, err := parsePlainText(plaintext)
+	if err != nil {
+		return "", err
+	}
+	if string(appid) != config.AppID {
 		return "", errors.New("appid mismatch")
 	}
-	cipherText = cipherText[20:appidOffset]
 	var ticketData ztype.Map
 	ticketData, err = ParseXML2Map(cipherText)
 	if err != nil {
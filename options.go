@@ -2,6 +2,7 @@ package wechat
 
 import (
 	"strings"
+	"time"
 )
 
 type WechatOptions struct {
@@ -30,3 +31,31 @@ func WithToggleAgentID(agentID string) Option {
 		}
 	}
 }
+
+// WithAuthRedirectHosts 网页授权允许的回调及跳转域名，支持 *.example.com 通配子域名，未设置时默认为 WithRedirectDomain 的域名
+func WithAuthRedirectHosts(hosts ...string) Option {
+	return func(e *Engine) {
+		e.authRedirectHosts = hosts
+	}
+}
+
+// WithAuthStateTTL 网页授权 state 有效期
+func WithAuthStateTTL(ttl time.Duration) Option {
+	return func(e *Engine) {
+		e.authStateTTL = ttl
+	}
+}
+
+// WithWeappSessionTTL 小程序登录会话有效期
+func WithWeappSessionTTL(ttl time.Duration) Option {
+	return func(e *Engine) {
+		e.weappSessionTTL = ttl
+	}
+}
+
+// WithWeappWatermarkMaxAge 小程序开放数据水印的最大有效时长，为 0 时不校验
+func WithWeappWatermarkMaxAge(maxAge time.Duration) Option {
+	return func(e *Engine) {
+		e.weappWatermarkMaxAge = maxAge
+	}
+}
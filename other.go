@@ -7,8 +7,8 @@ import (
 )
 
 // GetAuthUserInfo 获取用户信息
-// 企业微信需要使用 user_ticket 代替 openid
-func (e *Engine) GetAuthUserInfo(openid, authAccessToken string) (json *zjson.Res, err error) {
+// 企业微信需要使用 user_ticket 代替 openid，lang 仅公众号有效
+func (e *Engine) GetAuthUserInfo(openid, authAccessToken string, lang ...string) (json *zjson.Res, err error) {
 	u := zstring.Buffer(6)
 	u.WriteString(e.apiURL)
 	switch true {
@@ -21,6 +21,10 @@ func (e *Engine) GetAuthUserInfo(openid, authAccessToken string) (json *zjson.Re
 		u.WriteString(authAccessToken)
 		u.WriteString("&openid=")
 		u.WriteString(openid)
+		if len(lang) > 0 && lang[0] != "" {
+			u.WriteString("&lang=")
+			u.WriteString(lang[0])
+		}
 		return httpResProcess(http.Get(u.String()))
 	}
 
@@ -0,0 +1,136 @@
+package wechat
+
+import (
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sohaha/zlsgo/zjson"
+)
+
+type (
+	// QrCodeTicket 二维码 ticket
+	QrCodeTicket struct {
+		Ticket        string `json:"ticket"`
+		ExpireSeconds int    `json:"expire_seconds"`
+		URL           string `json:"url"`
+	}
+)
+
+const (
+	QrActionScene         = "QR_SCENE"
+	QrActionStrScene      = "QR_STR_SCENE"
+	QrActionLimitScene    = "QR_LIMIT_SCENE"
+	QrActionLimitStrScene = "QR_LIMIT_STR_SCENE"
+
+	showQrCodeURL      = "https://mp.weixin.qq.com/cgi-bin/showqrcode?ticket="
+	qrSceneEventPrefix = "qrscene_"
+	qrMaxExpire        = 30 * 24 * time.Hour
+	qrLimitMaxSceneID  = 100000
+	qrMaxSceneStrLen   = 64
+)
+
+var (
+	ErrQrSceneID  = errors.New("invalid qrcode scene_id")
+	ErrQrSceneStr = errors.New("the length of qrcode scene_str must be between 1 and 64")
+)
+
+// CreateTempQrCode 生成临时整型参数二维码，有效期最长 30 天
+func (m *Mp) CreateTempQrCode(sceneID int, expire time.Duration) (QrCodeTicket, error) {
+	if sceneID <= 0 {
+		return QrCodeTicket{}, ErrQrSceneID
+	}
+	return m.createQrCode(QrActionScene, map[string]interface{}{"scene_id": sceneID}, expire)
+}
+
+// CreateTempStrQrCode 生成临时字符串参数二维码，有效期最长 30 天
+func (m *Mp) CreateTempStrQrCode(sceneStr string, expire time.Duration) (QrCodeTicket, error) {
+	if l := len(sceneStr); l == 0 || l > qrMaxSceneStrLen {
+		return QrCodeTicket{}, ErrQrSceneStr
+	}
+	return m.createQrCode(QrActionStrScene, map[string]interface{}{"scene_str": sceneStr}, expire)
+}
+
+// CreateLimitQrCode 生成永久整型参数二维码，scene_id 取值 1 到 100000
+func (m *Mp) CreateLimitQrCode(sceneID int) (QrCodeTicket, error) {
+	if sceneID <= 0 || sceneID > qrLimitMaxSceneID {
+		return QrCodeTicket{}, ErrQrSceneID
+	}
+	return m.createQrCode(QrActionLimitScene, map[string]interface{}{"scene_id": sceneID}, 0)
+}
+
+// CreateLimitStrQrCode 生成永久字符串参数二维码
+func (m *Mp) CreateLimitStrQrCode(sceneStr string) (QrCodeTicket, error) {
+	if l := len(sceneStr); l == 0 || l > qrMaxSceneStrLen {
+		return QrCodeTicket{}, ErrQrSceneStr
+	}
+	return m.createQrCode(QrActionLimitStrScene, map[string]interface{}{"scene_str": sceneStr}, 0)
+}
+
+func (m *Mp) createQrCode(action string, scene map[string]interface{}, expire time.Duration) (ticket QrCodeTicket, err error) {
+	data := map[string]interface{}{
+		"action_name": action,
+		"action_info": map[string]interface{}{"scene": scene},
+	}
+	if action == QrActionScene || action == QrActionStrScene {
+		if expire > qrMaxExpire {
+			expire = qrMaxExpire
+		}
+		if expire > 0 {
+			data["expire_seconds"] = int(expire / time.Second)
+		}
+	}
+	var res *zjson.Res
+	res, err = m.post("/cgi-bin/qrcode/create", data)
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &ticket)
+	return
+}
+
+// QrCodeURL 通过 ticket 获取二维码图片地址
+func QrCodeURL(ticket string) string {
+	return showQrCodeURL + url.QueryEscape(ticket)
+}
+
+// GetQrCodeImage 通过 ticket 下载二维码图片
+func GetQrCodeImage(ticket string) ([]byte, error) {
+	b, err := httpProcess(http.Get(QrCodeURL(ticket)))
+	if err == errNoJSON {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	_, err = CheckResError(b)
+	if err == nil {
+		err = errors.New("qrcode image not found")
+	}
+	return nil, err
+}
+
+// WriteQrCodeImage 通过 ticket 下载二维码图片并写入 w
+func WriteQrCodeImage(ticket string, w io.Writer) error {
+	b, err := GetQrCodeImage(ticket)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// QrScene 解析扫描带参数二维码事件的场景值，未关注用户扫码关注时会去除 qrscene_ 前缀
+func (t *ReplySt) QrScene() (scene string, ok bool) {
+	switch t.Event {
+	case "SCAN":
+		return t.EventKey, t.EventKey != ""
+	case "subscribe":
+		if strings.HasPrefix(t.EventKey, qrSceneEventPrefix) {
+			return strings.TrimPrefix(t.EventKey, qrSceneEventPrefix), true
+		}
+	}
+	return "", false
+}
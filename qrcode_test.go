@@ -0,0 +1,53 @@
+package wechat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestCreateQrCode(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	mp := &Mp{AppID: "wx_qrcode"}
+	e := New(mp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		return `{"ticket":"gQH47joAAAAAAAAAASxodHRwOi8vd2VpeGluLnFxLmNvbS9xL2taZ2Z3TVRtNzJXV1Brb3ZhYmJJAAIEZ23sUwMEmm3sUw==","expire_seconds":60,"url":"http://weixin.qq.com/q/kZgfwMTm72WWPkovabbI"}`
+	})
+	defer api.Close()
+
+	ticket, err := mp.CreateTempQrCode(123, time.Minute)
+	tt.Equal(true, err == nil)
+	tt.Equal(60, ticket.ExpireSeconds)
+	tt.Equal("http://weixin.qq.com/q/kZgfwMTm72WWPkovabbI", ticket.URL)
+	req := api.Last()
+	tt.Equal("/cgi-bin/qrcode/create", req.Path)
+	tt.Equal(QrActionScene, req.JSON("action_name").String())
+	tt.Equal(123, req.JSON("action_info.scene.scene_id").Int())
+	tt.Equal(60, req.JSON("expire_seconds").Int())
+
+	_, err = mp.CreateTempStrQrCode("test", 60*24*time.Hour)
+	tt.Equal(true, err == nil)
+	req = api.Last()
+	tt.Equal(QrActionStrScene, req.JSON("action_name").String())
+	tt.Equal("test", req.JSON("action_info.scene.scene_str").String())
+	tt.Equal(int(qrMaxExpire/time.Second), req.JSON("expire_seconds").Int())
+
+	_, err = mp.CreateLimitQrCode(qrLimitMaxSceneID)
+	tt.Equal(true, err == nil)
+	req = api.Last()
+	tt.Equal(QrActionLimitScene, req.JSON("action_name").String())
+	tt.Equal(qrLimitMaxSceneID, req.JSON("action_info.scene.scene_id").Int())
+	tt.Equal(false, req.JSON("expire_seconds").Exists())
+
+	_, err = mp.CreateLimitStrQrCode("test")
+	tt.Equal(true, err == nil)
+	req = api.Last()
+	tt.Equal(QrActionLimitStrScene, req.JSON("action_name").String())
+	tt.Equal("test", req.JSON("action_info.scene.scene_str").String())
+	tt.Equal(false, req.JSON("expire_seconds").Exists())
+
+	_, err = mp.CreateLimitQrCode(qrLimitMaxSceneID + 1)
+	tt.Equal(ErrQrSceneID, err)
+	tt.Equal(4, len(api.Requests()))
+}
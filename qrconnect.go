@@ -0,0 +1,91 @@
+package wechat
+
+import (
+	"net/url"
+)
+
+type (
+	// WxLoginConfig 内嵌二维码 new WxLogin 参数
+	WxLoginConfig struct {
+		SelfRedirect bool   `json:"self_redirect"`
+		ID           string `json:"id"`
+		AppID        string `json:"appid"`
+		Scope        string `json:"scope"`
+		RedirectURI  string `json:"redirect_uri"`
+		State        string `json:"state"`
+		Style        string `json:"style,omitempty"`
+		Href         string `json:"href,omitempty"`
+	}
+
+	// WebsiteLogin 网站应用扫码登录结果
+	WebsiteLogin struct {
+		Token    OAuthToken
+		UserInfo UserInfo
+		ReturnTo string
+	}
+)
+
+func (e *Engine) checkWebsiteRedirect(redirectURI, returnTo string) error {
+	if !e.IsWebsite() {
+		return ErrOAuthNotSupport
+	}
+	if len(e.redirectHosts()) > 0 && !e.allowRedirect(redirectURI, false) {
+		return ErrAuthRedirectHost
+	}
+	if returnTo != "" && !e.allowRedirect(returnTo, true) {
+		return ErrAuthRedirectHost
+	}
+	return nil
+}
+
+// GetQrConnectURL 生成网站应用扫码登录地址，state 与 returnTo 会在回调时通过 LoginWebsite 取回
+func (e *Engine) GetQrConnectURL(redirectURI, state, returnTo string) (string, error) {
+	if err := e.checkWebsiteRedirect(redirectURI, returnTo); err != nil {
+		return "", err
+	}
+	authState, err := e.NewAuthState(state, returnTo)
+	if err != nil {
+		return "", err
+	}
+	return e.getOauthRedirect(redirectURI, authState, ScopeLogin), nil
+}
+
+// GetWxLoginConfig 生成网站内嵌二维码登录的 JS 参数，containerID 为二维码容器 id
+func (e *Engine) GetWxLoginConfig(containerID, redirectURI, state, returnTo string) (WxLoginConfig, error) {
+	if err := e.checkWebsiteRedirect(redirectURI, returnTo); err != nil {
+		return WxLoginConfig{}, err
+	}
+	authState, err := e.NewAuthState(state, returnTo)
+	if err != nil {
+		return WxLoginConfig{}, err
+	}
+	return WxLoginConfig{
+		ID:          containerID,
+		AppID:       e.GetAppID(),
+		Scope:       string(ScopeLogin),
+		RedirectURI: url.QueryEscape(redirectURI),
+		State:       authState,
+	}, nil
+}
+
+// LoginWebsite 处理网站应用扫码登录回调，校验 state 后获取 access_token 与包含 unionid 的用户信息
+func (e *Engine) LoginWebsite(code, authState, state string, lang ...string) (login WebsiteLogin, err error) {
+	if !e.IsWebsite() {
+		return login, ErrOAuthNotSupport
+	}
+	var bound AuthState
+	bound, err = e.VerifyAuthState(authState)
+	if err != nil {
+		return
+	}
+	if bound.State != state {
+		return login, ErrAuthState
+	}
+	login.ReturnTo = bound.ReturnTo
+	login.Token, err = e.GetOAuthToken(code)
+	if err != nil {
+		return
+	}
+	login.UserInfo, err = e.GetOAuthUserInfo(login.Token.OpenID, login.Token.AccessToken, lang...)
+	return
+}
@@ -63,5 +63,16 @@ func (q *Qy) getJsapiTicket() (data *zhttp.Res, err error) {
 	}
 	return http.Post(fmt.Sprintf(
 		"%s/cgi-bin/get_jsapi_ticket?access_token=%s",
-		QyAPIURL, token))
+		q.engine.apiURL, token))
+}
+
+func (q *Qy) getAgentJsapiTicket() (data *zhttp.Res, err error) {
+	var token string
+	token, err = q.engine.GetAccessToken()
+	if err != nil {
+		return nil, err
+	}
+	return http.Get(fmt.Sprintf(
+		"%s/cgi-bin/ticket/get?access_token=%s&type=agent_config",
+		q.engine.apiURL, token))
 }
@@ -1,11 +1,13 @@
 package wechat
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"strconv"
 	"time"
 
+	"github.com/sohaha/zlsgo/zjson"
 	"github.com/sohaha/zlsgo/zstring"
 	"github.com/sohaha/zlsgo/ztype"
 )
@@ -31,8 +33,12 @@ type (
 		MsgId        int
 		MsgType      string
 		Event        string
+		EventKey     string
 		ToUserName   string
 
+		// 扫描带参数二维码
+		Ticket string
+
 		MediaId string
 		// image
 		PicUrl string
@@ -58,11 +64,24 @@ type (
 		Description string
 		Url         string
 
+		// PUBLISHJOBFINISH
+		PublishEventInfo *PublishStatus `xml:"PublishEventInfo"`
+
+		// 小程序卡片
+		AppID    string `xml:"AppId"`
+		PagePath string
+		ThumbUrl string
+
+		// user_enter_tempsession
+		SessionFrom string
+
 		// Qy
 		AgentID    string `xml:"AgentID"`
 		isEncrypt  bool
 		receiverID string
 		received   *ReceivedSt
+		raw        []byte
+		isJSON     bool
 	}
 )
 
@@ -129,14 +148,22 @@ func (r *ReceivedSt) Data() (data *ReplySt, err error) {
 	if r.data != nil {
 		return r.data, nil
 	}
+	// 小程序消息推送可配置为 JSON 格式
+	isJSON := isJSONBody(r.bodyData)
 	if r.isEncrypt {
-		var arr ztype.Map
-		arr, err = ParseXML2Map(r.bodyData)
-		if err != nil {
-			return
+		var encrypt string
+		if isJSON {
+			encrypt = zjson.ParseBytes(r.bodyData).Get("Encrypt").String()
+		} else {
+			var arr ztype.Map
+			arr, err = ParseXML2Map(r.bodyData)
+			if err != nil {
+				return
+			}
+			encrypt = arr.Get("Encrypt").String()
 		}
 		var plaintext []byte
-		plaintext, err = aesDecrypt(arr.Get("Encrypt").String(), r.encodingAesKey)
+		plaintext, err = aesDecrypt(encrypt, r.encodingAesKey)
 		if err != nil {
 			return
 		}
@@ -147,7 +174,7 @@ func (r *ReceivedSt) Data() (data *ReplySt, err error) {
 		}
 
 		log.Debug(zstring.Bytes2String(plaintext))
-		err = xml.Unmarshal(plaintext, &data)
+		data, err = unmarshalReply(plaintext, isJSON)
 		if err == nil {
 			data.received = r
 			data.isEncrypt = true
@@ -155,11 +182,35 @@ func (r *ReceivedSt) Data() (data *ReplySt, err error) {
 		}
 	} else {
 		// log.Debug(zstring.Bytes2String(r.bodyData))
-		err = xml.Unmarshal(r.bodyData, &data)
+		data, err = unmarshalReply(r.bodyData, isJSON)
+	}
+	return
+}
+
+func unmarshalReply(b []byte, isJSON bool) (data *ReplySt, err error) {
+	if isJSON {
+		err = json.Unmarshal(b, &data)
+	} else {
+		err = xml.Unmarshal(b, &data)
+	}
+	if err == nil {
+		data.raw = b
+		data.isJSON = isJSON
 	}
 	return
 }
 
+// Unmarshal 将原始消息解析到自定义结构体
+func (t *ReplySt) Unmarshal(v interface{}) error {
+	if len(t.raw) == 0 {
+		return errors.New("message data is empty")
+	}
+	if t.isJSON {
+		return json.Unmarshal(t.raw, v)
+	}
+	return xml.Unmarshal(t.raw, v)
+}
+
 func (t *ReplySt) ReplyCustom(fn func(r *ReplySt) (xml string)) string {
 	reply := t.encrypt(fn(t))
 	return reply
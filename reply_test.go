@@ -0,0 +1,94 @@
+package wechat
+
+import (
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestReplyPublishEvent(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	r := &ReceivedSt{bodyData: []byte(`<xml><ToUserName><![CDATA[gh_4d00ed8d6399]]></ToUserName><FromUserName><![CDATA[oV5CrjpxgaGXNHIQigzNlgLTnwic]]></FromUserName><CreateTime>1481013459</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[PUBLISHJOBFINISH]]></Event><PublishEventInfo><publish_id>2247503051</publish_id><publish_status>2</publish_status><article_id><![CDATA[b5O2OUs25HBxRceL7hfReg-U9QGeq9zQjiDvy]]></article_id><article_detail><count>1</count><item><idx>1</idx><article_url><![CDATA[ARTICLE_URL]]></article_url></item></article_detail><fail_idx>1</fail_idx><fail_idx>2</fail_idx></PublishEventInfo></xml>`)}
+	data, err := r.Data()
+	tt.Equal(true, err == nil)
+	tt.Equal(EventPublishJobFinish, data.Event)
+	tt.Equal("2247503051", data.PublishEventInfo.PublishID)
+	tt.Equal(PublishOriginalFail, data.PublishEventInfo.PublishStatus)
+	tt.Equal("ARTICLE_URL", data.PublishEventInfo.ArticleDetail.Item[0].ArticleURL)
+	tt.Equal([]int{1, 2}, data.PublishEventInfo.FailIdx)
+}
+
+func TestReplyQrScene(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	for body, scene := range map[string]string{
+		`<xml><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[subscribe]]></Event><EventKey><![CDATA[qrscene_123123]]></EventKey><Ticket><![CDATA[TICKET]]></Ticket></xml>`: "123123",
+		`<xml><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[SCAN]]></Event><EventKey><![CDATA[from_poster]]></EventKey><Ticket><![CDATA[TICKET]]></Ticket></xml>`:         "from_poster",
+		`<xml><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[subscribe]]></Event></xml>`:                                                                                   "",
+	} {
+		data, err := (&ReceivedSt{bodyData: []byte(body)}).Data()
+		tt.Equal(true, err == nil)
+		s, ok := data.QrScene()
+		tt.Equal(scene, s)
+		tt.Equal(scene != "", ok)
+	}
+}
+
+func TestReplyCardEvent(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	data, err := (&ReceivedSt{bodyData: []byte(`<xml><ToUserName><![CDATA[toUser]]></ToUserName><FromUserName><![CDATA[FromUser]]></FromUserName><CreateTime>123456789</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[user_consume_card]]></Event><CardId><![CDATA[4ZqbrcYtNz1w]]></CardId><UserCardCode><![CDATA[12312312]]></UserCardCode><ConsumeSource><![CDATA[FROM_API]]></ConsumeSource><StaffOpenId><![CDATA[oFS7Fjl0WsZ9AMZqrI80nbIq8xrA]]></StaffOpenId></xml>`)}).Data()
+	tt.Equal(true, err == nil)
+	event, err := data.CardEvent()
+	tt.Equal(true, err == nil)
+	tt.Equal("4ZqbrcYtNz1w", event.CardID)
+	tt.Equal("12312312", event.UserCardCode)
+	tt.Equal("FROM_API", event.ConsumeSource)
+	tt.Equal("oFS7Fjl0WsZ9AMZqrI80nbIq8xrA", event.StaffOpenID)
+
+	data.Event = "subscribe"
+	_, err = data.CardEvent()
+	tt.Equal(true, err != nil)
+}
+
+func TestReplyWeappJSON(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	body := `{"ToUserName":"gh_weapp","FromUserName":"openid","CreateTime":1482048670,"MsgType":"miniprogrampage","MsgId":1234567890123456,"Title":"title","AppId":"wx_weapp","PagePath":"pages/index","ThumbUrl":"https://example.com/a.png","ThumbMediaId":"media_id"}`
+	data, err := (&ReceivedSt{bodyData: []byte(body)}).Data()
+	tt.Equal(true, err == nil)
+	tt.Equal("miniprogrampage", data.MsgType)
+	tt.Equal("wx_weapp", data.AppID)
+	tt.Equal("pages/index", data.PagePath)
+	tt.Equal(1234567890123456, data.MsgId)
+
+	key := "abcdefghijklmnopqrstuvwxyz0123456789ABCDEFG"
+	encrypt, err := aesEncrypt(MarshalPlainText(`{"ToUserName":"gh_weapp","FromUserName":"openid","MsgType":"event","Event":"user_enter_tempsession","SessionFrom":"order"}`, "wx_weapp", "1234567890123456"), key)
+	tt.Equal(true, err == nil)
+	data, err = (&ReceivedSt{isEncrypt: true, encodingAesKey: key, bodyData: []byte(`{"ToUserName":"gh_weapp","Encrypt":"` + string(encrypt) + `"}`)}).Data()
+	tt.Equal(true, err == nil)
+	tt.Equal(EventUserEnterTempSession, data.Event)
+	tt.Equal("order", data.SessionFrom)
+
+	data, err = (&ReceivedSt{bodyData: []byte(`{"FromUserName":"openid","MsgType":"event","Event":"subscribe_msg_popup_event","List":{"TemplateId":"tpl","SubscribeStatusString":"accept","PopupScene":"2"}}`)}).Data()
+	tt.Equal(true, err == nil)
+	items, err := data.SubscribeMsgPopup()
+	tt.Equal(true, err == nil)
+	tt.Equal([]SubscribeMsgPopupItem{{TemplateID: "tpl", SubscribeStatusString: SubscribeAccept, PopupScene: 2}}, items)
+}
+
+func TestReplyTradeManageEvent(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	data, err := (&ReceivedSt{bodyData: []byte(`<xml><ToUserName><![CDATA[gh_weapp]]></ToUserName><FromUserName><![CDATA[openid]]></FromUserName><CreateTime>1670000000</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[trade_manage_order_settlement]]></Event><transaction_id><![CDATA[4200001]]></transaction_id><merchant_id><![CDATA[1230000109]]></merchant_id><merchant_trade_no><![CDATA[order_1]]></merchant_trade_no><pay_time>1670000000</pay_time><shipped_time>1670000100</shipped_time><confirm_receive_method>1</confirm_receive_method></xml>`)}).Data()
+	tt.Equal(true, err == nil)
+	event, err := data.TradeManageEvent()
+	tt.Equal(true, err == nil)
+	tt.Equal("4200001", event.TransactionID)
+	tt.Equal("order_1", event.MerchantTradeNo)
+	tt.Equal(int64(1670000100), event.ShippedTime)
+	tt.Equal(1, event.ConfirmReceiveMethod)
+
+	data, err = (&ReceivedSt{bodyData: []byte(`{"ToUserName":"gh_weapp","FromUserName":"openid","MsgType":"event","Event":"trade_manage_remind_shipping","transaction_id":"4200002","merchant_trade_no":"order_2","pay_time":1670000000,"msg":"请尽快发货"}`)}).Data()
+	tt.Equal(true, err == nil)
+	event, err = data.TradeManageEvent()
+	tt.Equal(true, err == nil)
+	tt.Equal("4200002", event.TransactionID)
+	tt.Equal("请尽快发货", event.Msg)
+}
@@ -0,0 +1,226 @@
+package wechat
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/sohaha/zlsgo/zjson"
+	"github.com/sohaha/zlsgo/zstring"
+)
+
+type (
+	// SecCheckResult 内容安全综合结果
+	SecCheckResult struct {
+		Suggest string `json:"suggest" xml:"suggest"`
+		Label   int    `json:"label" xml:"label"`
+	}
+
+	// SecCheckDetail 内容安全详细检测结果
+	SecCheckDetail struct {
+		Strategy string `json:"strategy" xml:"strategy"`
+		ErrCode  int    `json:"errcode" xml:"errcode"`
+		Suggest  string `json:"suggest" xml:"suggest"`
+		Label    int    `json:"label" xml:"label"`
+		Keyword  string `json:"keyword" xml:"keyword"`
+		Prob     int    `json:"prob" xml:"prob"`
+	}
+
+	// MsgSecCheckResult 文本内容安全检测结果
+	MsgSecCheckResult struct {
+		TraceID string           `json:"trace_id"`
+		Result  SecCheckResult   `json:"result"`
+		Detail  []SecCheckDetail `json:"detail"`
+	}
+
+	// MsgSecCheckOptions 文本内容安全检测选项
+	MsgSecCheckOptions struct {
+		Title     string
+		Nickname  string
+		Signature string
+	}
+
+	MsgSecCheckOption func(*MsgSecCheckOptions)
+
+	// MediaCheckTrace 等待异步检测结果的多媒体
+	MediaCheckTrace struct {
+		TraceID    string `json:"trace_id"`
+		MediaURL   string `json:"media_url"`
+		MediaType  int    `json:"media_type"`
+		OpenID     string `json:"openid"`
+		Scene      int    `json:"scene"`
+		CreateTime int64  `json:"create_time"`
+	}
+
+	// MediaCheckEvent 多媒体异步检测结果事件
+	MediaCheckEvent struct {
+		AppID   string           `xml:"appid" json:"appid"`
+		TraceID string           `xml:"trace_id" json:"trace_id"`
+		Version int              `xml:"version" json:"version"`
+		Detail  []SecCheckDetail `xml:"detail" json:"detail"`
+		ErrCode int              `xml:"errcode" json:"errcode"`
+		ErrMsg  string           `xml:"errmsg" json:"errmsg"`
+		Result  SecCheckResult   `xml:"result" json:"result"`
+	}
+)
+
+const (
+	// EventMediaCheck 多媒体异步检测结果事件
+	EventMediaCheck = "wxa_media_check"
+
+	SecSceneProfile   = 1
+	SecSceneComment   = 2
+	SecSceneForum     = 3
+	SecSceneSocialLog = 4
+
+	SecMediaAudio = 1
+	SecMediaImage = 2
+
+	SecSuggestPass   = "pass"
+	SecSuggestReview = "review"
+	SecSuggestRisky  = "risky"
+
+	mediaCheckTraceTTL = time.Hour
+)
+
+var ErrMediaCheckTrace = errors.New("media check trace not found or already handled")
+
+// WithMsgSecCheckTitle 文本标题
+func WithMsgSecCheckTitle(title string) MsgSecCheckOption {
+	return func(o *MsgSecCheckOptions) {
+		o.Title = title
+	}
+}
+
+// WithMsgSecCheckNickname 用户昵称
+func WithMsgSecCheckNickname(nickname string) MsgSecCheckOption {
+	return func(o *MsgSecCheckOptions) {
+		o.Nickname = nickname
+	}
+}
+
+// WithMsgSecCheckSignature 个性签名，仅在资料类场景有效
+func WithMsgSecCheckSignature(signature string) MsgSecCheckOption {
+	return func(o *MsgSecCheckOptions) {
+		o.Signature = signature
+	}
+}
+
+// Pass 是否通过检测
+func (r SecCheckResult) Pass() bool {
+	return r.Suggest == SecSuggestPass
+}
+
+// MsgSecCheck 文本内容安全检测，openid 需在近两小时访问过小程序
+func (m *Weapp) MsgSecCheck(openid string, scene int, content string, opts ...MsgSecCheckOption) (result MsgSecCheckResult, err error) {
+	o := MsgSecCheckOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	data := map[string]interface{}{
+		"content": content,
+		"version": 2,
+		"scene":   scene,
+		"openid":  openid,
+	}
+	if o.Title != "" {
+		data["title"] = o.Title
+	}
+	if o.Nickname != "" {
+		data["nickname"] = o.Nickname
+	}
+	if o.Signature != "" {
+		data["signature"] = o.Signature
+	}
+	var res *zjson.Res
+	res, err = m.post("/wxa/msg_sec_check", data)
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &result)
+	return
+}
+
+// MediaCheckAsync 异步检测音频或图片，检测结果通过 wxa_media_check 事件推送
+func (m *Weapp) MediaCheckAsync(openid string, scene int, mediaURL string, mediaType int) (traceID string, err error) {
+	var res *zjson.Res
+	res, err = m.post("/wxa/media_check_async", map[string]interface{}{
+		"media_url":  mediaURL,
+		"media_type": mediaType,
+		"version":    2,
+		"scene":      scene,
+		"openid":     openid,
+	})
+	if err != nil {
+		return
+	}
+	traceID = res.Get("trace_id").String()
+	if traceID == "" {
+		return "", errors.New("trace_id parsing failed")
+	}
+	b, err := json.Marshal(MediaCheckTrace{
+		TraceID:    traceID,
+		MediaURL:   mediaURL,
+		MediaType:  mediaType,
+		OpenID:     openid,
+		Scene:      scene,
+		CreateTime: time.Now().Unix(),
+	})
+	if err != nil {
+		return
+	}
+	m.engine.cache.Set(cacheMediaCheck+traceID, zstring.Bytes2String(b), uint(mediaCheckTraceTTL/time.Second))
+	return
+}
+
+// OnMediaCheck 注册多媒体异步检测结果回调
+func (m *Weapp) OnMediaCheck(fn func(trace MediaCheckTrace, event MediaCheckEvent)) {
+	m.mediaCheckMu.Lock()
+	m.mediaCheckHandler = fn
+	m.mediaCheckMu.Unlock()
+}
+
+// HandleMediaCheck 处理 wxa_media_check 事件，关联等待中的检测并调用回调，非该事件时返回 false
+// 同一 trace_id 仅处理一次，重复推送返回 ErrMediaCheckTrace
+func (m *Weapp) HandleMediaCheck(t *ReplySt) (handled bool, err error) {
+	if t.Event != EventMediaCheck {
+		return false, nil
+	}
+	var e *Engine
+	if e, err = m.checkEngine(); err != nil {
+		return
+	}
+	var event MediaCheckEvent
+	if event, err = t.MediaCheck(); err != nil {
+		return
+	}
+	key := cacheMediaCheck + event.TraceID
+	raw, err := e.cache.GetString(key)
+	if err != nil || raw == "" {
+		return true, ErrMediaCheckTrace
+	}
+	// 以删除成功为准，重复推送的事件只处理一次
+	if _, err = e.cache.Delete(key); err != nil {
+		return true, ErrMediaCheckTrace
+	}
+	var trace MediaCheckTrace
+	if err = json.Unmarshal(zstring.String2Bytes(raw), &trace); err != nil {
+		return true, ErrMediaCheckTrace
+	}
+	m.mediaCheckMu.RLock()
+	fn := m.mediaCheckHandler
+	m.mediaCheckMu.RUnlock()
+	if fn != nil {
+		fn(trace, event)
+	}
+	return true, nil
+}
+
+// MediaCheck 解析多媒体异步检测结果事件
+func (t *ReplySt) MediaCheck() (event MediaCheckEvent, err error) {
+	if t.Event != EventMediaCheck {
+		return event, errors.New("not a " + EventMediaCheck + " event")
+	}
+	err = t.Unmarshal(&event)
+	return
+}
@@ -0,0 +1,81 @@
+package wechat
+
+import (
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestHandleMediaCheck(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_media_check"}
+	New(weapp)
+	weapp.engine.cache.Set(cacheMediaCheck+"trace_1", `{"trace_id":"trace_1","media_url":"https://example.com/a.png","media_type":2,"openid":"openid"}`, 60)
+
+	var (
+		gotTrace MediaCheckTrace
+		gotEvent MediaCheckEvent
+	)
+	weapp.OnMediaCheck(func(trace MediaCheckTrace, event MediaCheckEvent) {
+		gotTrace, gotEvent = trace, event
+	})
+
+	data, err := (&ReceivedSt{bodyData: []byte(`<xml><ToUserName><![CDATA[gh_38cc49f9733b]]></ToUserName><FromUserName><![CDATA[oH1fu0FdHqpToe2T6gBj0WyB8iS1]]></FromUserName><CreateTime>1626959646</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[wxa_media_check]]></Event><appid><![CDATA[wx_media_check]]></appid><trace_id><![CDATA[trace_1]]></trace_id><version>2</version><detail><strategy><![CDATA[content_model]]></strategy><errcode>0</errcode><suggest><![CDATA[risky]]></suggest><label>20006</label><prob>90</prob></detail><errcode>0</errcode><errmsg><![CDATA[ok]]></errmsg><result><suggest><![CDATA[risky]]></suggest><label>20006</label></result></xml>`)}).Data()
+	tt.Equal(true, err == nil)
+
+	handled, err := weapp.HandleMediaCheck(data)
+	tt.Equal(true, handled)
+	tt.Equal(true, err == nil)
+	tt.Equal("https://example.com/a.png", gotTrace.MediaURL)
+	tt.Equal(SecSuggestRisky, gotEvent.Result.Suggest)
+	tt.Equal(false, gotEvent.Result.Pass())
+	tt.Equal(20006, gotEvent.Detail[0].Label)
+
+	_, err = weapp.HandleMediaCheck(data)
+	tt.Equal(ErrMediaCheckTrace, err)
+
+	data.Event = "subscribe"
+	handled, _ = weapp.HandleMediaCheck(data)
+	tt.Equal(false, handled)
+}
+
+func TestMediaCheckAsync(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_media_check_async"}
+	e := New(weapp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		if r.Path == "/wxa/msg_sec_check" {
+			return `{"errcode":0,"errmsg":"ok","result":{"suggest":"pass","label":100},"detail":[],"trace_id":"trace_msg"}`
+		}
+		return `{"errcode":0,"errmsg":"ok","trace_id":"trace_2"}`
+	})
+	defer api.Close()
+
+	result, err := weapp.MsgSecCheck("openid", SecSceneComment, "hello", WithMsgSecCheckTitle("title"))
+	tt.Equal(true, err == nil)
+	tt.Equal(true, result.Result.Pass())
+	req := api.Last()
+	tt.Equal(2, req.JSON("version").Int())
+	tt.Equal("title", req.JSON("title").String())
+	tt.Equal(false, req.JSON("nickname").Exists())
+
+	traceID, err := weapp.MediaCheckAsync("openid", SecSceneProfile, "https://example.com/a.mp3", SecMediaAudio)
+	tt.Equal(true, err == nil)
+	tt.Equal("trace_2", traceID)
+	req = api.Last()
+	tt.Equal("/wxa/media_check_async", req.Path)
+	tt.Equal(SecMediaAudio, req.JSON("media_type").Int())
+
+	// 回调可在处理事件时并发注册
+	data, err := (&ReceivedSt{bodyData: []byte(`<xml><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[wxa_media_check]]></Event><appid><![CDATA[wx_media_check_async]]></appid><trace_id><![CDATA[trace_2]]></trace_id><version>2</version><errcode>0</errcode><errmsg><![CDATA[ok]]></errmsg><result><suggest><![CDATA[pass]]></suggest><label>100</label></result></xml>`)}).Data()
+	tt.Equal(true, err == nil)
+	done := make(chan struct{})
+	go func() {
+		weapp.OnMediaCheck(func(trace MediaCheckTrace, event MediaCheckEvent) {})
+		close(done)
+	}()
+	handled, err := weapp.HandleMediaCheck(data)
+	<-done
+	tt.Equal(true, handled)
+	tt.Equal(true, err == nil)
+}
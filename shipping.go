@@ -0,0 +1,275 @@
+package wechat
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sohaha/zlsgo/zhttp"
+	"github.com/sohaha/zlsgo/zjson"
+)
+
+type (
+	// ShippingOrderKey 订单，使用商户单号时需填写 MchID 与 OutTradeNo
+	ShippingOrderKey struct {
+		OrderNumberType int    `json:"order_number_type"`
+		TransactionID   string `json:"transaction_id,omitempty"`
+		MchID           string `json:"mchid,omitempty"`
+		OutTradeNo      string `json:"out_trade_no,omitempty"`
+	}
+
+	// ShippingContact 联系方式，顺丰必填，需掩码
+	ShippingContact struct {
+		ConsignorContact string `json:"consignor_contact,omitempty"`
+		ReceiverContact  string `json:"receiver_contact,omitempty"`
+	}
+
+	// ShippingPayer 支付者
+	ShippingPayer struct {
+		OpenID string `json:"openid"`
+	}
+
+	// ShippingItem 物流信息
+	ShippingItem struct {
+		TrackingNo     string           `json:"tracking_no,omitempty"`
+		ExpressCompany string           `json:"express_company,omitempty"`
+		ItemDesc       string           `json:"item_desc"`
+		Contact        *ShippingContact `json:"contact,omitempty"`
+	}
+
+	// ShippingInfo 发货信息
+	ShippingInfo struct {
+		OrderKey      ShippingOrderKey `json:"order_key"`
+		LogisticsType int              `json:"logistics_type"`
+		DeliveryMode  int              `json:"delivery_mode"`
+		// IsAllDelivered 分拆发货时是否已全部发货，false 表示还有后续发货
+		IsAllDelivered bool           `json:"is_all_delivered"`
+		ShippingList   []ShippingItem `json:"shipping_list"`
+		// UploadTime 为空时使用当前时间
+		UploadTime string        `json:"upload_time"`
+		Payer      ShippingPayer `json:"payer"`
+	}
+
+	// ShippingSubOrder 合单的子单发货信息
+	ShippingSubOrder struct {
+		OrderKey      ShippingOrderKey `json:"order_key"`
+		LogisticsType int              `json:"logistics_type"`
+		DeliveryMode  int              `json:"delivery_mode"`
+		// IsAllDelivered 分拆发货时是否已全部发货，false 表示还有后续发货
+		IsAllDelivered bool           `json:"is_all_delivered"`
+		ShippingList   []ShippingItem `json:"shipping_list"`
+	}
+
+	// CombinedShippingInfo 合单发货信息
+	CombinedShippingInfo struct {
+		OrderKey   ShippingOrderKey   `json:"order_key"`
+		SubOrders  []ShippingSubOrder `json:"sub_orders"`
+		UploadTime string             `json:"upload_time"`
+		Payer      ShippingPayer      `json:"payer"`
+	}
+
+	// ShippingOrder 订单发货状态
+	ShippingOrder struct {
+		TransactionID   string `json:"transaction_id"`
+		MerchantID      string `json:"merchant_id"`
+		SubMerchantID   string `json:"sub_merchant_id"`
+		MerchantTradeNo string `json:"merchant_trade_no"`
+		Description     string `json:"description"`
+		PaidAmount      int    `json:"paid_amount"`
+		OpenID          string `json:"openid"`
+		TradeCreateTime int64  `json:"trade_create_time"`
+		PayTime         int64  `json:"pay_time"`
+		InComplaint     bool   `json:"in_complaint"`
+		OrderState      int    `json:"order_state"`
+		Shipping        struct {
+			DeliveryMode        int    `json:"delivery_mode"`
+			LogisticsType       int    `json:"logistics_type"`
+			FinishShipping      bool   `json:"finish_shipping"`
+			GoodsDesc           string `json:"goods_desc"`
+			FinishShippingCount int    `json:"finish_shipping_count"`
+			ShippingList        []struct {
+				TrackingNo     string          `json:"tracking_no"`
+				ExpressCompany string          `json:"express_company"`
+				UploadTime     int64           `json:"upload_time"`
+				Contact        ShippingContact `json:"contact"`
+			} `json:"shipping_list"`
+		} `json:"shipping"`
+	}
+
+	// ShippingOrderQuery 订单列表查询条件，零值字段不作为查询条件
+	ShippingOrderQuery struct {
+		PayTimeBegin time.Time
+		PayTimeEnd   time.Time
+		OrderState   int
+		OpenID       string
+		PageSize     int
+	}
+
+	// TradeManageEvent 发货信息管理事件
+	TradeManageEvent struct {
+		TransactionID           string `xml:"transaction_id" json:"transaction_id"`
+		MerchantID              string `xml:"merchant_id" json:"merchant_id"`
+		SubMerchantID           string `xml:"sub_merchant_id" json:"sub_merchant_id"`
+		MerchantTradeNo         string `xml:"merchant_trade_no" json:"merchant_trade_no"`
+		PayTime                 int64  `xml:"pay_time" json:"pay_time"`
+		Msg                     string `xml:"msg" json:"msg"`
+		ShippedTime             int64  `xml:"shipped_time" json:"shipped_time"`
+		EstimatedSettlementTime int64  `xml:"estimated_settlement_time" json:"estimated_settlement_time"`
+		ConfirmReceiveMethod    int    `xml:"confirm_receive_method" json:"confirm_receive_method"`
+		ConfirmReceiveTime      int64  `xml:"confirm_receive_time" json:"confirm_receive_time"`
+		SettlementTime          int64  `xml:"settlement_time" json:"settlement_time"`
+	}
+)
+
+const (
+	// EventTradeManageRemindShipping 提醒发货事件
+	EventTradeManageRemindShipping = "trade_manage_remind_shipping"
+	// EventTradeManageOrderSettlement 订单将要结算或已结算事件
+	EventTradeManageOrderSettlement = "trade_manage_order_settlement"
+
+	ShippingOrderNumberOutTradeNo    = 1
+	ShippingOrderNumberTransactionID = 2
+
+	LogisticsExpress    = 1
+	LogisticsSameCity   = 2
+	LogisticsVirtual    = 3
+	LogisticsSelfPickup = 4
+
+	DeliveryUnified = 1
+	DeliverySplit   = 2
+
+	ShippingStateWaiting   = 1
+	ShippingStateShipped   = 2
+	ShippingStateConfirmed = 3
+	ShippingStateCompleted = 4
+	ShippingStateRefunded  = 5
+	ShippingStateSettling  = 6
+
+	shippingOrderMaxPageSize = 100
+)
+
+func shippingUploadTime(t string) string {
+	if t == "" {
+		return time.Now().Format(time.RFC3339)
+	}
+	return t
+}
+
+func (m *Weapp) shippingPost(path string, data interface{}) (*zjson.Res, error) {
+	return m.post("/wxa/sec/order/"+path, zhttp.BodyJSON(data))
+}
+
+// UploadShippingInfo 发货信息录入
+func (m *Weapp) UploadShippingInfo(info ShippingInfo) error {
+	info.UploadTime = shippingUploadTime(info.UploadTime)
+	_, err := m.shippingPost("upload_shipping_info", info)
+	return err
+}
+
+// UploadCombinedShippingInfo 合单发货信息录入
+func (m *Weapp) UploadCombinedShippingInfo(info CombinedShippingInfo) error {
+	info.UploadTime = shippingUploadTime(info.UploadTime)
+	_, err := m.shippingPost("upload_combined_shipping_info", info)
+	return err
+}
+
+// GetShippingOrder 查询订单发货状态，transactionID 为空时使用商户号与商户订单号查询
+func (m *Weapp) GetShippingOrder(transactionID, merchantID, merchantTradeNo string) (order ShippingOrder, err error) {
+	data := map[string]string{}
+	if transactionID != "" {
+		data["transaction_id"] = transactionID
+	} else {
+		data["merchant_id"] = merchantID
+		data["merchant_trade_no"] = merchantTradeNo
+	}
+	var res *zjson.Res
+	res, err = m.shippingPost("get_order", data)
+	if err != nil {
+		return
+	}
+	var result struct {
+		Order ShippingOrder `json:"order"`
+	}
+	err = unmarshalRes(res, &result)
+	return result.Order, err
+}
+
+// ShippingOrderForEach 遍历订单列表，fn 返回 false 时停止遍历
+func (m *Weapp) ShippingOrderForEach(query ShippingOrderQuery, fn func(order ShippingOrder) bool) error {
+	data := map[string]interface{}{}
+	if !query.PayTimeBegin.IsZero() || !query.PayTimeEnd.IsZero() {
+		payTime := map[string]int64{}
+		if !query.PayTimeBegin.IsZero() {
+			payTime["begin_time"] = query.PayTimeBegin.Unix()
+		}
+		if !query.PayTimeEnd.IsZero() {
+			payTime["end_time"] = query.PayTimeEnd.Unix()
+		}
+		data["pay_time_range"] = payTime
+	}
+	if query.OrderState > 0 {
+		data["order_state"] = query.OrderState
+	}
+	if query.OpenID != "" {
+		data["openid"] = query.OpenID
+	}
+	if query.PageSize <= 0 || query.PageSize > shippingOrderMaxPageSize {
+		query.PageSize = shippingOrderMaxPageSize
+	}
+	data["page_size"] = query.PageSize
+	return forEachCursor(func() (bool, bool, error) {
+		res, err := m.shippingPost("get_order_list", data)
+		if err != nil {
+			return false, false, err
+		}
+		var result struct {
+			OrderList []ShippingOrder `json:"order_list"`
+			LastIndex string          `json:"last_index"`
+			HasMore   bool            `json:"has_more"`
+		}
+		if err = unmarshalRes(res, &result); err != nil {
+			return false, false, err
+		}
+		stop := eachItem(len(result.OrderList), func(i int) bool { return fn(result.OrderList[i]) })
+		data["last_index"] = result.LastIndex
+		return result.HasMore && result.LastIndex != "", stop, nil
+	})
+}
+
+// NotifyConfirmReceive 确认收货提醒，transactionID 为空时使用商户号与商户订单号
+func (m *Weapp) NotifyConfirmReceive(transactionID, merchantID, merchantTradeNo string, receivedTime time.Time) error {
+	data := map[string]interface{}{"received_time": receivedTime.Unix()}
+	if transactionID != "" {
+		data["transaction_id"] = transactionID
+	} else {
+		data["merchant_id"] = merchantID
+		data["merchant_trade_no"] = merchantTradeNo
+	}
+	_, err := m.shippingPost("notify_confirm_receive", data)
+	return err
+}
+
+// SetShippingMsgJumpPath 设置消息跳转路径，用户点击发货消息时跳转到该页面
+func (m *Weapp) SetShippingMsgJumpPath(path string) error {
+	_, err := m.shippingPost("set_msg_jump_path", map[string]string{"path": path})
+	return err
+}
+
+// IsTradeManaged 查询小程序是否已开通发货信息管理服务
+func (m *Weapp) IsTradeManaged() (bool, error) {
+	res, err := m.shippingPost("is_trade_managed", map[string]string{"appid": m.AppID})
+	if err != nil {
+		return false, err
+	}
+	return res.Get("is_trade_managed").Bool(), nil
+}
+
+// TradeManageEvent 解析提醒发货与订单结算事件
+func (t *ReplySt) TradeManageEvent() (event TradeManageEvent, err error) {
+	switch t.Event {
+	case EventTradeManageRemindShipping, EventTradeManageOrderSettlement:
+		err = t.Unmarshal(&event)
+	default:
+		err = fmt.Errorf("not a trade manage event: %s", t.Event)
+	}
+	return
+}
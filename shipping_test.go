@@ -0,0 +1,79 @@
+package wechat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestShippingOrderForEach(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_shipping_each"}
+	e := New(weapp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		if r.JSON("last_index").String() == "" {
+			return `{"errcode":0,"order_list":[{"transaction_id":"t1"},{"transaction_id":"t2"}],"last_index":"idx","has_more":true}`
+		}
+		return `{"errcode":0,"order_list":[{"transaction_id":"t3"}],"last_index":"idx2","has_more":false}`
+	})
+	defer api.Close()
+
+	var ids []string
+	err := weapp.ShippingOrderForEach(ShippingOrderQuery{PayTimeBegin: time.Unix(100, 0), OrderState: 2}, func(order ShippingOrder) bool {
+		ids = append(ids, order.TransactionID)
+		return true
+	})
+	tt.Equal(true, err == nil)
+	tt.Equal([]string{"t1", "t2", "t3"}, ids)
+	reqs := api.Requests()
+	tt.Equal(2, len(reqs))
+	tt.Equal("/wxa/sec/order/get_order_list", reqs[0].Path)
+	tt.Equal(100, reqs[0].JSON("pay_time_range.begin_time").Int())
+	tt.Equal(false, reqs[0].JSON("pay_time_range.end_time").Exists())
+	tt.Equal(2, reqs[0].JSON("order_state").Int())
+	tt.Equal(100, reqs[0].JSON("page_size").Int())
+	tt.Equal("idx", reqs[1].JSON("last_index").String())
+
+	ids = ids[:0]
+	err = weapp.ShippingOrderForEach(ShippingOrderQuery{}, func(order ShippingOrder) bool {
+		ids = append(ids, order.TransactionID)
+		return false
+	})
+	tt.Equal(true, err == nil)
+	tt.Equal([]string{"t1"}, ids)
+	tt.Equal(3, len(api.Requests()))
+}
+
+func TestUploadShippingInfo(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_shipping_upload"}
+	e := New(weapp)
+	api := newMockAPI(e, nil)
+	defer api.Close()
+
+	err := weapp.UploadShippingInfo(ShippingInfo{
+		OrderKey:      ShippingOrderKey{OrderNumberType: 2, TransactionID: "t1"},
+		LogisticsType: 1,
+		DeliveryMode:  DeliverySplit,
+		ShippingList:  []ShippingItem{{TrackingNo: "sf1", ExpressCompany: "SF", ItemDesc: "item"}},
+		Payer:         ShippingPayer{OpenID: "openid"},
+	})
+	tt.Equal(true, err == nil)
+	req := api.Last()
+	tt.Equal(true, req.JSON("is_all_delivered").Exists())
+	tt.Equal(false, req.JSON("is_all_delivered").Bool())
+	tt.Equal(DeliverySplit, req.JSON("delivery_mode").Int())
+	tt.Equal("sf1", req.JSON("shipping_list.0.tracking_no").String())
+
+	err = weapp.UploadCombinedShippingInfo(CombinedShippingInfo{
+		OrderKey:  ShippingOrderKey{OrderNumberType: 2, TransactionID: "t2"},
+		SubOrders: []ShippingSubOrder{{DeliveryMode: DeliverySplit}, {DeliveryMode: DeliverySplit, IsAllDelivered: true}},
+		Payer:     ShippingPayer{OpenID: "openid"},
+	})
+	tt.Equal(true, err == nil)
+	req = api.Last()
+	tt.Equal(true, req.JSON("sub_orders.0.is_all_delivered").Exists())
+	tt.Equal(false, req.JSON("sub_orders.0.is_all_delivered").Bool())
+	tt.Equal(true, req.JSON("sub_orders.1.is_all_delivered").Bool())
+}
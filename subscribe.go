@@ -0,0 +1,266 @@
+package wechat
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sohaha/zlsgo/zhttp"
+	"github.com/sohaha/zlsgo/zjson"
+	"github.com/sohaha/zlsgo/zstring"
+)
+
+type (
+	// SubscribeData 订阅消息模板内容，key 为模板字段名，如 thing1
+	SubscribeData map[string]string
+
+	// SubscribeMessage 小程序订阅消息
+	SubscribeMessage struct {
+		ToUser     string        `json:"touser"`
+		TemplateID string        `json:"template_id"`
+		Page       string        `json:"page,omitempty"`
+		Data       SubscribeData `json:"data"`
+		// MiniprogramState 跳转小程序类型，默认为正式版
+		MiniprogramState string `json:"miniprogram_state,omitempty"`
+		Lang             string `json:"lang,omitempty"`
+	}
+
+	// SubscribeTemplate 个人模板
+	SubscribeTemplate struct {
+		PriTmplID string `json:"priTmplId"`
+		Title     string `json:"title"`
+		Content   string `json:"content"`
+		Example   string `json:"example"`
+		Type      int    `json:"type"`
+	}
+
+	// SubscribeMsgPopupItem 用户订阅弹窗操作结果
+	SubscribeMsgPopupItem struct {
+		TemplateID            string `xml:"TemplateId"`
+		SubscribeStatusString string
+		PopupScene            int
+	}
+)
+
+const (
+	// EventSubscribeMsgPopup 用户操作订阅弹窗事件
+	EventSubscribeMsgPopup = "subscribe_msg_popup_event"
+
+	SubscribeAccept = "accept"
+	SubscribeReject = "reject"
+	SubscribeBan    = "ban"
+	SubscribeFilter = "filter"
+
+	MiniprogramStateDeveloper = "developer"
+	MiniprogramStateTrial     = "trial"
+	MiniprogramStateFormal    = "formal"
+
+	subscribeQuotaTTL   = 365 * 24 * time.Hour
+	subscribePendingTTL = 5 * time.Minute
+
+	subscribeSourceClient = 'c'
+	subscribeSourceEvent  = 'e'
+	subscribeTemplatesTTL = 10 * time.Minute
+)
+
+var (
+	ErrSubscribeQuota = errors.New("user has no remaining subscribe message authorization")
+	subscribeMu       sync.Mutex
+)
+
+// MarshalJSON 转换为 {"key": {"value": "..."}} 格式
+func (d SubscribeData) MarshalJSON() ([]byte, error) {
+	data := make(map[string]map[string]string, len(d))
+	for k, v := range d {
+		data[k] = map[string]string{"value": v}
+	}
+	return json.Marshal(data)
+}
+
+func subscribeQuotaKey(openid, templateID string) string {
+	return cacheSubscribeQuota + openid + "|" + templateID
+}
+
+// SubscribeQuota 获取用户对模板的剩余订阅次数，未记录过时 known 为 false
+func (m *Weapp) SubscribeQuota(openid, templateID string) (quota int, known bool) {
+	if m.engine == nil {
+		return 0, false
+	}
+	s, err := m.engine.cache.GetString(subscribeQuotaKey(openid, templateID))
+	if err != nil || s == "" {
+		return 0, false
+	}
+	quota, err = strconv.Atoi(s)
+	return quota, err == nil
+}
+
+func subscribePendingKey(openid, templateID string) string {
+	return cacheSubscribePending + openid + "|" + templateID
+}
+
+func (m *Weapp) setSubscribeQuota(openid, templateID string, quota int) {
+	m.engine.cache.Set(subscribeQuotaKey(openid, templateID), strconv.Itoa(quota), uint(subscribeQuotaTTL/time.Second))
+}
+
+// RecordSubscribe 记录用户订阅结果，results 为 wx.requestSubscribeMessage 的返回结果，每次 accept 增加一次订阅次数
+func (m *Weapp) RecordSubscribe(openid string, results map[string]string) error {
+	return m.recordSubscribe(openid, results, subscribeSourceClient)
+}
+
+// recordSubscribe 同一次授权会同时收到前端结果与 subscribe_msg_popup_event 事件，
+// 已被另一来源记录过的 accept 不再重复累加
+func (m *Weapp) recordSubscribe(openid string, results map[string]string, source byte) error {
+	if _, err := m.checkEngine(); err != nil {
+		return err
+	}
+	subscribeMu.Lock()
+	defer subscribeMu.Unlock()
+	for templateID, status := range results {
+		switch status {
+		case SubscribeAccept:
+			if m.matchSubscribePending(openid, templateID, source) {
+				continue
+			}
+			quota, _ := m.SubscribeQuota(openid, templateID)
+			m.setSubscribeQuota(openid, templateID, quota+1)
+		case SubscribeReject, SubscribeBan:
+			if _, known := m.SubscribeQuota(openid, templateID); !known {
+				m.setSubscribeQuota(openid, templateID, 0)
+			}
+		}
+	}
+	return nil
+}
+
+// matchSubscribePending 消费另一来源未匹配的 accept 记录，没有时记录本次 accept 等待匹配
+func (m *Weapp) matchSubscribePending(openid, templateID string, source byte) bool {
+	key := subscribePendingKey(openid, templateID)
+	pending, _ := m.engine.cache.GetString(key)
+	var other byte = subscribeSourceEvent
+	if source == subscribeSourceEvent {
+		other = subscribeSourceClient
+	}
+	if i := strings.IndexByte(pending, other); i >= 0 {
+		pending = pending[:i] + pending[i+1:]
+		if pending == "" {
+			_, _ = m.engine.cache.Delete(key)
+		} else {
+			m.engine.cache.Set(key, pending, uint(subscribePendingTTL/time.Second))
+		}
+		return true
+	}
+	m.engine.cache.Set(key, pending+string(source), uint(subscribePendingTTL/time.Second))
+	return false
+}
+
+// SubscribeMsgPopup 解析用户操作订阅弹窗事件
+func (t *ReplySt) SubscribeMsgPopup() (items []SubscribeMsgPopupItem, err error) {
+	if t.Event != EventSubscribeMsgPopup {
+		return nil, errors.New("not a " + EventSubscribeMsgPopup + " event")
+	}
+	if t.isJSON {
+		// JSON 格式中 List 只有一项时为对象，PopupScene 为字符串
+		list := zjson.ParseBytes(t.raw).Get("List")
+		parse := func(item *zjson.Res) {
+			items = append(items, SubscribeMsgPopupItem{
+				TemplateID:            item.Get("TemplateId").String(),
+				SubscribeStatusString: item.Get("SubscribeStatusString").String(),
+				PopupScene:            item.Get("PopupScene").Int(),
+			})
+		}
+		if list.Get("TemplateId").Exists() {
+			parse(list)
+		} else {
+			list.ForEach(func(_, item *zjson.Res) bool {
+				parse(item)
+				return true
+			})
+		}
+		return
+	}
+	var event struct {
+		List []SubscribeMsgPopupItem `xml:"SubscribeMsgPopupEvent>List"`
+	}
+	err = t.Unmarshal(&event)
+	return event.List, err
+}
+
+// HandleSubscribeMsgPopup 处理 subscribe_msg_popup_event 事件并记录订阅结果，非该事件时返回 false
+func (m *Weapp) HandleSubscribeMsgPopup(t *ReplySt) (handled bool, err error) {
+	if t.Event != EventSubscribeMsgPopup {
+		return false, nil
+	}
+	var items []SubscribeMsgPopupItem
+	if items, err = t.SubscribeMsgPopup(); err != nil {
+		return
+	}
+	results := make(map[string]string, len(items))
+	for _, item := range items {
+		results[item.TemplateID] = item.SubscribeStatusString
+	}
+	return true, m.recordSubscribe(t.FromUserName, results, subscribeSourceEvent)
+}
+
+// SendSubscribeMessage 发送订阅消息，已知用户没有剩余订阅次数时直接返回 ErrSubscribeQuota
+func (m *Weapp) SendSubscribeMessage(msg SubscribeMessage) error {
+	if _, err := m.checkEngine(); err != nil {
+		return err
+	}
+	subscribeMu.Lock()
+	if quota, known := m.SubscribeQuota(msg.ToUser, msg.TemplateID); known {
+		if quota <= 0 {
+			subscribeMu.Unlock()
+			return ErrSubscribeQuota
+		}
+		m.setSubscribeQuota(msg.ToUser, msg.TemplateID, quota-1)
+	}
+	subscribeMu.Unlock()
+
+	_, err := m.post("/cgi-bin/message/subscribe/send", zhttp.BodyJSON(msg))
+	if err == nil {
+		return nil
+	}
+	switch ErrorCode(err) {
+	case 43101:
+		subscribeMu.Lock()
+		m.setSubscribeQuota(msg.ToUser, msg.TemplateID, 0)
+		subscribeMu.Unlock()
+	default:
+		// 发送失败时未消耗订阅次数
+		subscribeMu.Lock()
+		if quota, known := m.SubscribeQuota(msg.ToUser, msg.TemplateID); known {
+			m.setSubscribeQuota(msg.ToUser, msg.TemplateID, quota+1)
+		}
+		subscribeMu.Unlock()
+	}
+	return err
+}
+
+// GetSubscribeTemplates 获取个人模板列表，结果会缓存 10 分钟
+func (m *Weapp) GetSubscribeTemplates(refresh ...bool) (list []SubscribeTemplate, err error) {
+	var e *Engine
+	if e, err = m.checkEngine(); err != nil {
+		return
+	}
+	if len(refresh) > 0 && refresh[0] {
+		_, _ = e.cache.Delete(cacheSubscribeTemplates)
+	}
+	var raw string
+	if raw, err = e.cache.GetString(cacheSubscribeTemplates); err != nil || raw == "" {
+		var res *zjson.Res
+		res, err = m.get("/wxaapi/newtmpl/gettemplate")
+		if err != nil {
+			return
+		}
+		raw = res.Get("data").String()
+		if raw == "" {
+			raw = "[]"
+		}
+		e.cache.Set(cacheSubscribeTemplates, raw, uint(subscribeTemplatesTTL/time.Second))
+	}
+	err = json.Unmarshal(zstring.String2Bytes(raw), &list)
+	return
+}
@@ -0,0 +1,82 @@
+package wechat
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestSubscribeLedger(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_subscribe"}
+	New(weapp)
+
+	_, known := weapp.SubscribeQuota("openid", "tpl_1")
+	tt.Equal(false, known)
+
+	tt.Equal(true, weapp.RecordSubscribe("openid", map[string]string{"errMsg": "requestSubscribeMessage:ok", "tpl_1": SubscribeAccept, "tpl_2": SubscribeReject}) == nil)
+	quota, known := weapp.SubscribeQuota("openid", "tpl_1")
+	tt.Equal(true, known)
+	tt.Equal(1, quota)
+
+	tt.Equal(ErrSubscribeQuota, weapp.SendSubscribeMessage(SubscribeMessage{ToUser: "openid", TemplateID: "tpl_2"}))
+
+	data, err := (&ReceivedSt{bodyData: []byte(`<xml><ToUserName><![CDATA[gh_123456789abc]]></ToUserName><FromUserName><![CDATA[openid]]></FromUserName><CreateTime>1620973045</CreateTime><MsgType><![CDATA[event]]></MsgType><Event><![CDATA[subscribe_msg_popup_event]]></Event><SubscribeMsgPopupEvent><List><TemplateId><![CDATA[tpl_1]]></TemplateId><SubscribeStatusString><![CDATA[accept]]></SubscribeStatusString><PopupScene>0</PopupScene></List><List><TemplateId><![CDATA[tpl_2]]></TemplateId><SubscribeStatusString><![CDATA[accept]]></SubscribeStatusString><PopupScene>0</PopupScene></List></SubscribeMsgPopupEvent></xml>`)}).Data()
+	tt.Equal(true, err == nil)
+	handled, err := weapp.HandleSubscribeMsgPopup(data)
+	tt.Equal(true, handled)
+	tt.Equal(true, err == nil)
+	// 前端结果与事件为同一次授权，不重复累加
+	quota, _ = weapp.SubscribeQuota("openid", "tpl_1")
+	tt.Equal(1, quota)
+	quota, _ = weapp.SubscribeQuota("openid", "tpl_2")
+	tt.Equal(1, quota)
+
+	tt.Equal(true, weapp.RecordSubscribe("openid", map[string]string{"tpl_2": SubscribeAccept}) == nil)
+	quota, _ = weapp.SubscribeQuota("openid", "tpl_2")
+	tt.Equal(1, quota)
+	tt.Equal(true, weapp.RecordSubscribe("openid", map[string]string{"tpl_2": SubscribeAccept}) == nil)
+	quota, _ = weapp.SubscribeQuota("openid", "tpl_2")
+	tt.Equal(2, quota)
+
+	b, _ := json.Marshal(SubscribeData{"thing1": "hello"})
+	tt.Equal(`{"thing1":{"value":"hello"}}`, string(b))
+}
+
+func TestSendSubscribeMessage(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_subscribe_send"}
+	e := New(weapp)
+	errcode := 0
+	api := newMockAPI(e, func(r mockRequest) string {
+		if errcode != 0 {
+			return `{"errcode":` + strconv.Itoa(errcode) + `,"errmsg":"fail"}`
+		}
+		return ""
+	})
+	defer api.Close()
+
+	weapp.setSubscribeQuota("openid", "tpl", 2)
+	tt.Equal(true, weapp.SendSubscribeMessage(SubscribeMessage{ToUser: "openid", TemplateID: "tpl", Data: SubscribeData{"thing1": "hi"}}) == nil)
+	req := api.Last()
+	tt.Equal("/cgi-bin/message/subscribe/send", req.Path)
+	tt.Equal("ACCESS_TOKEN", req.Query.Get("access_token"))
+	tt.Equal("tpl", req.JSON("template_id").String())
+	tt.Equal("hi", req.JSON("data.thing1.value").String())
+	quota, _ := weapp.SubscribeQuota("openid", "tpl")
+	tt.Equal(1, quota)
+
+	errcode = 40003
+	tt.Equal(40003, ErrorCode(weapp.SendSubscribeMessage(SubscribeMessage{ToUser: "openid", TemplateID: "tpl"})))
+	quota, _ = weapp.SubscribeQuota("openid", "tpl")
+	tt.Equal(1, quota)
+
+	errcode = 43101
+	tt.Equal(43101, ErrorCode(weapp.SendSubscribeMessage(SubscribeMessage{ToUser: "openid", TemplateID: "tpl"})))
+	quota, _ = weapp.SubscribeQuota("openid", "tpl")
+	tt.Equal(0, quota)
+	tt.Equal(ErrSubscribeQuota, weapp.SendSubscribeMessage(SubscribeMessage{ToUser: "openid", TemplateID: "tpl"}))
+	tt.Equal(3, len(api.Requests()))
+}
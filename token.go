@@ -23,6 +23,8 @@ const (
 	ScopeUserinfo ScopeType = "snsapi_userinfo"
 	// ScopePrivateinfo 企业微信需要使用这个才能拿到用户的基本信息
 	ScopePrivateinfo ScopeType = "snsapi_privateinfo"
+	// ScopeLogin 网站应用扫码登录
+	ScopeLogin ScopeType = "snsapi_login"
 )
 
 func (e *Engine) GetAccessTokenExpiresInCountdown() float64 {
@@ -71,43 +73,63 @@ func (e *Engine) GetAccessToken() (string, error) {
 	return data.(string), nil
 }
 
-// Auth 用户授权
+// Auth 用户授权，state 会与随机生成的授权 state 绑定并在回调时校验
 func (e *Engine) Auth(c *znet.Context, state string, scope ScopeType) (*zjson.Res, bool, error) {
-	code := e.authCode(c, state, scope, "", "")
+	json, _, ok, err := e.auth(c, state, "", scope)
+	return json, ok, err
+}
+
+// AuthReturnTo 用户授权，授权完成后返回绑定的跳转地址
+// returnTo 为相对地址或在 WithAuthRedirectHosts 允许的域名内
+func (e *Engine) AuthReturnTo(c *znet.Context, returnTo string, scope ScopeType) (json *zjson.Res, redirect string, ok bool, err error) {
+	if returnTo != "" && !e.allowRedirect(returnTo, true) {
+		return nil, "", false, ErrAuthRedirectHost
+	}
+	return e.auth(c, "", returnTo, scope)
+}
+
+func (e *Engine) auth(c *znet.Context, state, returnTo string, scope ScopeType) (*zjson.Res, string, bool, error) {
+	code, _ := c.GetQuery("code")
 	if len(code) == 0 {
-		return nil, false, nil
+		return nil, "", false, e.authRedirect(c, state, returnTo, scope)
+	}
+	authState, _ := c.GetQuery("state")
+	bound, err := e.VerifyAuthState(authState)
+	if err != nil || bound.State != state {
+		return nil, "", false, ErrAuthState
 	}
 	json, err := e.GetAuthInfo(code)
 	if err != nil {
 		if httpErr, ok := err.(httpError); ok {
 			switch httpErr.Code {
 			case 41008, 40029, 40163:
-				if len(e.authCode(c, state, scope, "", code)) == 0 {
-					return nil, false, nil
+				if err = e.authRedirect(c, state, bound.ReturnTo, scope); err == nil {
+					return nil, "", false, nil
 				}
 			}
 		}
 	}
-	return json, true, err
+	return json, bound.ReturnTo, true, err
 }
 
-func (e *Engine) authCode(c *znet.Context, state string, scope ScopeType, uri, oldCode string) string {
-	code, _ := c.GetQuery("code")
-	if len(code) > 0 && code != oldCode {
-		return code
+func (e *Engine) authRedirect(c *znet.Context, state, returnTo string, scope ScopeType) error {
+	var uri string
+	if len(e.redirectDomain) > 0 {
+		uri = e.redirectDomain + c.Request.URL.String()
+	} else {
+		uri = c.Host(true)
 	}
-
-	if len(uri) == 0 {
-		if len(e.redirectDomain) > 0 {
-			uri = e.redirectDomain + c.Request.URL.String()
-		} else {
-			uri = c.Host(true)
-		}
+	if len(e.redirectHosts()) > 0 && !e.allowRedirect(uri, false) {
+		return ErrAuthRedirectHost
 	}
 
-	c.Redirect(e.getOauthRedirect(paramFilter(uri), state, scope))
+	authState, err := e.NewAuthState(state, returnTo)
+	if err != nil {
+		return err
+	}
+	c.Redirect(e.getOauthRedirect(paramFilter(uri), authState, scope))
 	c.Abort()
-	return ""
+	return nil
 }
 
 func (e *Engine) getOauthRedirect(callback string, state string, scope ScopeType) string {
@@ -115,7 +137,12 @@ func (e *Engine) getOauthRedirect(callback string, state string, scope ScopeType
 		scope = "snsapi_userinfo"
 	}
 	u := zstring.Buffer(10)
-	if e.IsQy() {
+	if e.IsWebsite() {
+		scope = ScopeLogin
+		u.WriteString(openURL)
+		u.WriteString("/connect/qrconnect?appid=")
+		u.WriteString(e.GetAppID())
+	} else if e.IsQy() {
 		u.WriteString("https://open.weixin.qq.com/connect/oauth2/authorize?appid=")
 		u.WriteString(e.GetAppID())
 		u.WriteString("&agentid=")
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/sha1"
 	"encoding/hex"
+	"encoding/json"
 	"encoding/xml"
 	"errors"
 	"fmt"
@@ -42,6 +43,40 @@ func transformSendData(v []interface{}) []interface{} {
 	return v
 }
 
+// forEachOffset 按 offset 自动翻页，page 返回本页条数、总数以及是否停止遍历
+func forEachOffset(page func(offset int) (count, total int, stop bool, err error)) error {
+	for offset := 0; ; {
+		count, total, stop, err := page(offset)
+		if err != nil || stop {
+			return err
+		}
+		offset += count
+		if count == 0 || offset >= total {
+			return nil
+		}
+	}
+}
+
+// forEachCursor 按游标自动翻页，page 返回是否还有下一页以及是否停止遍历
+func forEachCursor(page func() (more, stop bool, err error)) error {
+	for {
+		more, stop, err := page()
+		if err != nil || stop || !more {
+			return err
+		}
+	}
+}
+
+// eachItem 依次回调本页数据，fn 返回 false 时返回 true 表示停止遍历
+func eachItem(n int, fn func(i int) bool) (stop bool) {
+	for i := 0; i < n; i++ {
+		if !fn(i) {
+			return true
+		}
+	}
+	return false
+}
+
 func recurveFormatMap2XML(buf io.Writer, m ztype.Map) {
 	for k := range m {
 		_, _ = io.WriteString(buf, fmt.Sprintf("<%s>", k))
@@ -158,6 +193,23 @@ func CheckResError(v []byte) (*zjson.Res, error) {
 	return data, nil
 }
 
+// unmarshalRes 将接口响应解析为结构体
+func unmarshalRes(j *zjson.Res, v interface{}) error {
+	return json.Unmarshal(zstring.String2Bytes(j.String()), v)
+}
+
+// matchDomain 判断域名是否在列表中，列表支持 *.example.com 通配子域名
+func matchDomain(host string, domains []string) bool {
+	host = strings.ToLower(host)
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		if host == domain || (strings.HasPrefix(domain, "*.") && strings.HasSuffix(host, domain[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
 func paramFilter(uri string) string {
 	if u, err := url.Parse(uri); err == nil {
 		querys := u.Query()
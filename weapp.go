@@ -5,7 +5,9 @@ import (
 	"crypto/cipher"
 	"crypto/sha1"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/sohaha/zlsgo/zhttp"
 	"github.com/sohaha/zlsgo/zjson"
@@ -18,7 +20,11 @@ type (
 		AppSecret      string
 		EncodingAesKey string
 		Token          string
+		CloudEnv       string
 		engine         *Engine
+
+		mediaCheckMu      sync.RWMutex
+		mediaCheckHandler func(trace MediaCheckTrace, event MediaCheckEvent)
 	}
 )
 
@@ -32,6 +38,31 @@ func (m *Weapp) getEngine() *Engine {
 	return m.engine
 }
 
+func (m *Weapp) checkEngine() (*Engine, error) {
+	if m.engine == nil {
+		return nil, errors.New(`please use wechat.New(&wechat.Weapp{})`)
+	}
+	return m.engine, nil
+}
+
+// post 调用需要 access_token 的 POST 接口，path 为接口路径
+func (m *Weapp) post(path string, v ...interface{}) (*zjson.Res, error) {
+	e, err := m.checkEngine()
+	if err != nil {
+		return nil, err
+	}
+	return e.HttpAccessTokenPost(e.apiURL+path, v...)
+}
+
+// get 调用需要 access_token 的 GET 接口，path 为接口路径
+func (m *Weapp) get(path string, v ...interface{}) (*zjson.Res, error) {
+	e, err := m.checkEngine()
+	if err != nil {
+		return nil, err
+	}
+	return e.HttpAccessTokenGet(e.apiURL+path, v...)
+}
+
 func (m *Weapp) GetAppID() string {
 	return m.AppID
 }
@@ -51,7 +82,7 @@ func (m *Weapp) GetEncodingAesKey() string {
 func (m *Weapp) getAccessToken() (data []byte, err error) {
 	res, err := http.Post(fmt.Sprintf(
 		"%s/cgi-bin/token?grant_type=client_credential&appid=%s&secret=%s",
-		APIURL, m.AppID, m.AppSecret))
+		m.engine.apiURL, m.AppID, m.AppSecret))
 	if err != nil {
 		return
 	}
@@ -96,11 +127,24 @@ func (m *Weapp) Decrypt(seesionKey, iv, encryptedData string) (string, error) {
 	aesKey := byts[0]
 	ivRaw := byts[1]
 	cipherData := byts[2]
-	block, _ := aes.NewCipher(aesKey)
+	block, err := aes.NewCipher(aesKey)
+	if err != nil {
+		return "", err
+	}
 	blockSize := block.BlockSize()
+	if len(ivRaw) != blockSize {
+		return "", ErrDecryptData
+	}
+	if len(cipherData) == 0 || len(cipherData)%blockSize != 0 {
+		return "", ErrDecryptData
+	}
 	blockMode := cipher.NewCBCDecrypter(block, ivRaw)
 	plaintext := make([]byte, len(cipherData))
 	blockMode.CryptBlocks(plaintext, cipherData)
+	unpadding := int(plaintext[len(plaintext)-1])
+	if unpadding == 0 || unpadding > blockSize {
+		return "", ErrDecryptData
+	}
 	plaintext = PKCS7UnPadding(plaintext, blockSize)
 	return zstring.Bytes2String(plaintext), nil
 }
@@ -112,3 +156,21 @@ func (m *Weapp) Verify(seesionKey, rawData, signature string) bool {
 	sign := fmt.Sprintf("%x", h.Sum(nil))
 	return sign == signature
 }
+
+// GetPhoneNumber 通过手机号快速验证组件返回的 code 获取手机号
+func (m *Weapp) GetPhoneNumber(code string) (info WeappPhoneNumber, err error) {
+	var res *zjson.Res
+	res, err = m.post("/wxa/business/getuserphonenumber", map[string]string{"code": code})
+	if err != nil {
+		return
+	}
+	var data struct {
+		PhoneInfo WeappPhoneNumber `json:"phone_info"`
+	}
+	if err = unmarshalRes(res, &data); err != nil {
+		return
+	}
+	info = data.PhoneInfo
+	err = m.checkWatermark(info.Watermark)
+	return
+}
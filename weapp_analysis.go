@@ -0,0 +1,356 @@
+package wechat
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/sohaha/zlsgo/zjson"
+	"github.com/sohaha/zlsgo/zstring"
+)
+
+type (
+	// AnalysisSummary 小程序概况趋势
+	AnalysisSummary struct {
+		RefDate    string `json:"ref_date"`
+		VisitTotal int    `json:"visit_total"`
+		SharePv    int    `json:"share_pv"`
+		ShareUv    int    `json:"share_uv"`
+	}
+
+	// AnalysisVisitTrend 小程序访问趋势，周、月趋势的 RefDate 为时间范围，如 20170306-20170312
+	AnalysisVisitTrend struct {
+		RefDate         string  `json:"ref_date"`
+		SessionCnt      int     `json:"session_cnt"`
+		VisitPv         int     `json:"visit_pv"`
+		VisitUv         int     `json:"visit_uv"`
+		VisitUvNew      int     `json:"visit_uv_new"`
+		StayTimeUv      float64 `json:"stay_time_uv"`
+		StayTimeSession float64 `json:"stay_time_session"`
+		VisitDepth      float64 `json:"visit_depth"`
+	}
+
+	// AnalysisKeyValue 分布项
+	AnalysisKeyValue struct {
+		Key   int `json:"key"`
+		Value int `json:"value"`
+	}
+
+	// AnalysisRetain 小程序访问留存，Key 为第几天、周或月
+	AnalysisRetain struct {
+		RefDate    string             `json:"ref_date"`
+		VisitUvNew []AnalysisKeyValue `json:"visit_uv_new"`
+		VisitUv    []AnalysisKeyValue `json:"visit_uv"`
+	}
+
+	// AnalysisPortraitItem 用户画像属性
+	AnalysisPortraitItem struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Value int    `json:"value"`
+	}
+
+	// AnalysisPortraitDetail 用户画像分布
+	AnalysisPortraitDetail struct {
+		Province  []AnalysisPortraitItem `json:"province"`
+		City      []AnalysisPortraitItem `json:"city"`
+		Genders   []AnalysisPortraitItem `json:"genders"`
+		Platforms []AnalysisPortraitItem `json:"platforms"`
+		Devices   []AnalysisPortraitItem `json:"devices"`
+		Ages      []AnalysisPortraitItem `json:"ages"`
+	}
+
+	// AnalysisPortrait 小程序用户画像
+	AnalysisPortrait struct {
+		RefDate    string                 `json:"ref_date"`
+		VisitUvNew AnalysisPortraitDetail `json:"visit_uv_new"`
+		VisitUv    AnalysisPortraitDetail `json:"visit_uv"`
+	}
+
+	// AnalysisDistribution 小程序访问分布，Index 如 access_source_session_cnt
+	AnalysisDistribution struct {
+		RefDate  string             `json:"ref_date"`
+		Index    string             `json:"index"`
+		ItemList []AnalysisKeyValue `json:"item_list"`
+	}
+
+	// AnalysisVisitPage 小程序页面访问数据
+	AnalysisVisitPage struct {
+		RefDate        string  `json:"ref_date"`
+		PagePath       string  `json:"page_path"`
+		PageVisitPv    int     `json:"page_visit_pv"`
+		PageVisitUv    int     `json:"page_visit_uv"`
+		PageStaytimePv float64 `json:"page_staytime_pv"`
+		EntrypagePv    int     `json:"entrypage_pv"`
+		ExitpagePv     int     `json:"exitpage_pv"`
+		PageSharePv    int     `json:"page_share_pv"`
+		PageShareUv    int     `json:"page_share_uv"`
+	}
+
+	// PerformanceParam 性能数据查询参数
+	PerformanceParam struct {
+		Field string `json:"field"`
+		Value string `json:"value"`
+	}
+
+	// PerformanceLine 性能数据曲线
+	PerformanceLine struct {
+		Fields []struct {
+			RefDate string `json:"refdate"`
+			Value   string `json:"value"`
+		} `json:"fields"`
+	}
+
+	// PerformanceTable 性能数据指标
+	PerformanceTable struct {
+		ID    string            `json:"id"`
+		Zh    string            `json:"zh"`
+		Lines []PerformanceLine `json:"lines"`
+	}
+)
+
+const analysisDateLayout = "20060102"
+
+var (
+	ErrAnalysisWeekRange     = errors.New("weekly analysis range must start on Monday and end on Sunday")
+	ErrAnalysisMonthRange    = errors.New("monthly analysis range must start on the first day and end on the last day of a month")
+	ErrAnalysisPortraitRange = errors.New("user portrait supports only the last 1, 7 or 30 days")
+	ErrPerformanceRange      = errors.New("invalid performance time range")
+)
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// weekRanges 按自然周拆分，begin 必须为周一，end 必须为周日
+func weekRanges(begin, end time.Time) ([][2]time.Time, error) {
+	if begin.IsZero() || end.IsZero() {
+		return nil, codeError(61500)
+	}
+	if begin.Weekday() != time.Monday || end.Weekday() != time.Sunday {
+		return nil, ErrAnalysisWeekRange
+	}
+	return splitDateRange(begin, end, 7)
+}
+
+// monthRanges 按自然月拆分，begin 必须为月初，end 必须为月末
+func monthRanges(begin, end time.Time) ([][2]time.Time, error) {
+	if begin.IsZero() || end.IsZero() {
+		return nil, codeError(61500)
+	}
+	begin, end = truncateDay(begin), truncateDay(end)
+	if begin.Day() != 1 || end.AddDate(0, 0, 1).Day() != 1 {
+		return nil, ErrAnalysisMonthRange
+	}
+	if end.Before(begin) {
+		return nil, codeError(61501)
+	}
+	ranges := make([][2]time.Time, 0)
+	for from := begin; from.Before(end); from = from.AddDate(0, 1, 0) {
+		ranges = append(ranges, [2]time.Time{from, from.AddDate(0, 1, -1)})
+	}
+	return ranges, nil
+}
+
+func (m *Weapp) analysis(name string, ranges [][2]time.Time, fn func(res *zjson.Res) error) error {
+	for i := range ranges {
+		res, err := m.post("/datacube/"+name, map[string]string{
+			"begin_date": ranges[i][0].Format(analysisDateLayout),
+			"end_date":   ranges[i][1].Format(analysisDateLayout),
+		})
+		if err != nil {
+			return err
+		}
+		if err = fn(res); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDailySummary 获取小程序概况趋势，按天查询
+func (m *Weapp) GetDailySummary(begin, end time.Time) (list []AnalysisSummary, err error) {
+	var ranges [][2]time.Time
+	if ranges, err = splitDateRange(begin, end, 1); err != nil {
+		return
+	}
+	err = m.analysis("getweanalysisappiddailysummarytrend", ranges, func(res *zjson.Res) error {
+		var data struct {
+			List []AnalysisSummary `json:"list"`
+		}
+		err := unmarshalRes(res, &data)
+		list = append(list, data.List...)
+		return err
+	})
+	return
+}
+
+// GetDailyVisitTrend 获取小程序日访问趋势，按天查询
+func (m *Weapp) GetDailyVisitTrend(begin, end time.Time) (list []AnalysisVisitTrend, err error) {
+	var ranges [][2]time.Time
+	if ranges, err = splitDateRange(begin, end, 1); err != nil {
+		return
+	}
+	return m.getVisitTrend("getweanalysisappiddailyvisittrend", ranges)
+}
+
+// GetWeeklyVisitTrend 获取小程序周访问趋势，begin 为周一，end 为周日
+func (m *Weapp) GetWeeklyVisitTrend(begin, end time.Time) (list []AnalysisVisitTrend, err error) {
+	var ranges [][2]time.Time
+	if ranges, err = weekRanges(begin, end); err != nil {
+		return
+	}
+	return m.getVisitTrend("getweanalysisappidweeklyvisittrend", ranges)
+}
+
+// GetMonthlyVisitTrend 获取小程序月访问趋势，begin 为月初，end 为月末
+func (m *Weapp) GetMonthlyVisitTrend(begin, end time.Time) (list []AnalysisVisitTrend, err error) {
+	var ranges [][2]time.Time
+	if ranges, err = monthRanges(begin, end); err != nil {
+		return
+	}
+	return m.getVisitTrend("getweanalysisappidmonthlyvisittrend", ranges)
+}
+
+func (m *Weapp) getVisitTrend(name string, ranges [][2]time.Time) (list []AnalysisVisitTrend, err error) {
+	err = m.analysis(name, ranges, func(res *zjson.Res) error {
+		var data struct {
+			List []AnalysisVisitTrend `json:"list"`
+		}
+		err := unmarshalRes(res, &data)
+		list = append(list, data.List...)
+		return err
+	})
+	return
+}
+
+// GetDailyRetain 获取小程序日留存，按天查询
+func (m *Weapp) GetDailyRetain(begin, end time.Time) (list []AnalysisRetain, err error) {
+	var ranges [][2]time.Time
+	if ranges, err = splitDateRange(begin, end, 1); err != nil {
+		return
+	}
+	return m.getRetain("getweanalysisappiddailyretaininfo", ranges)
+}
+
+// GetWeeklyRetain 获取小程序周留存，begin 为周一，end 为周日
+func (m *Weapp) GetWeeklyRetain(begin, end time.Time) (list []AnalysisRetain, err error) {
+	var ranges [][2]time.Time
+	if ranges, err = weekRanges(begin, end); err != nil {
+		return
+	}
+	return m.getRetain("getweanalysisappidweeklyretaininfo", ranges)
+}
+
+// GetMonthlyRetain 获取小程序月留存，begin 为月初，end 为月末
+func (m *Weapp) GetMonthlyRetain(begin, end time.Time) (list []AnalysisRetain, err error) {
+	var ranges [][2]time.Time
+	if ranges, err = monthRanges(begin, end); err != nil {
+		return
+	}
+	return m.getRetain("getweanalysisappidmonthlyretaininfo", ranges)
+}
+
+func (m *Weapp) getRetain(name string, ranges [][2]time.Time) (list []AnalysisRetain, err error) {
+	err = m.analysis(name, ranges, func(res *zjson.Res) error {
+		var data AnalysisRetain
+		err := unmarshalRes(res, &data)
+		list = append(list, data)
+		return err
+	})
+	return
+}
+
+// GetUserPortrait 获取截至 end 近 days 天的用户画像，days 只能为 1、7 或 30
+func (m *Weapp) GetUserPortrait(end time.Time, days int) (portrait AnalysisPortrait, err error) {
+	if end.IsZero() {
+		return portrait, codeError(61500)
+	}
+	switch days {
+	case 1, 7, 30:
+	default:
+		return portrait, ErrAnalysisPortraitRange
+	}
+	end = truncateDay(end)
+	ranges := [][2]time.Time{{end.AddDate(0, 0, 1-days), end}}
+	err = m.analysis("getweanalysisappiduserportrait", ranges, func(res *zjson.Res) error {
+		return unmarshalRes(res, &portrait)
+	})
+	return
+}
+
+// GetVisitDistribution 获取小程序访问分布，按天查询
+func (m *Weapp) GetVisitDistribution(begin, end time.Time) (list []AnalysisDistribution, err error) {
+	var ranges [][2]time.Time
+	if ranges, err = splitDateRange(begin, end, 1); err != nil {
+		return
+	}
+	err = m.analysis("getweanalysisappidvisitdistribution", ranges, func(res *zjson.Res) error {
+		var data struct {
+			RefDate string                 `json:"ref_date"`
+			List    []AnalysisDistribution `json:"list"`
+		}
+		err := unmarshalRes(res, &data)
+		for i := range data.List {
+			data.List[i].RefDate = data.RefDate
+		}
+		list = append(list, data.List...)
+		return err
+	})
+	return
+}
+
+// GetVisitPage 获取小程序页面访问数据，按天查询
+func (m *Weapp) GetVisitPage(begin, end time.Time) (list []AnalysisVisitPage, err error) {
+	var ranges [][2]time.Time
+	if ranges, err = splitDateRange(begin, end, 1); err != nil {
+		return
+	}
+	err = m.analysis("getweanalysisappidvisitpage", ranges, func(res *zjson.Res) error {
+		var data struct {
+			RefDate string              `json:"ref_date"`
+			List    []AnalysisVisitPage `json:"list"`
+		}
+		err := unmarshalRes(res, &data)
+		for i := range data.List {
+			data.List[i].RefDate = data.RefDate
+		}
+		list = append(list, data.List...)
+		return err
+	})
+	return
+}
+
+// GetPerformance 获取小程序启动性能等运维数据，module 为查询的数据类型，如 10022
+func (m *Weapp) GetPerformance(module string, begin, end time.Time, params ...PerformanceParam) (tables []PerformanceTable, err error) {
+	if begin.IsZero() || end.IsZero() || end.Before(begin) {
+		return nil, ErrPerformanceRange
+	}
+	if params == nil {
+		params = []PerformanceParam{}
+	}
+	var res *zjson.Res
+	res, err = m.post("/wxa/business/performance/boot", map[string]interface{}{
+		"time": map[string]int64{
+			"begin_timestamp": begin.Unix(),
+			"end_timestamp":   end.Unix(),
+		},
+		"module": module,
+		"params": params,
+	})
+	if err != nil {
+		return
+	}
+	// data 为 JSON 字符串
+	var data struct {
+		Body struct {
+			Tables []PerformanceTable `json:"tables"`
+		} `json:"body"`
+	}
+	if raw := res.Get("data").String(); raw != "" {
+		if err = json.Unmarshal(zstring.String2Bytes(raw), &data); err != nil {
+			return
+		}
+	}
+	return data.Body.Tables, nil
+}
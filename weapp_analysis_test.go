@@ -0,0 +1,36 @@
+package wechat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestAnalysisRanges(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	day := func(s string) time.Time {
+		d, _ := time.ParseInLocation("2006-01-02", s, time.Local)
+		return d
+	}
+
+	ranges, err := weekRanges(day("2024-01-01"), day("2024-01-14"))
+	tt.Equal(true, err == nil)
+	tt.Equal(2, len(ranges))
+	tt.Equal(day("2024-01-08"), ranges[1][0])
+	_, err = weekRanges(day("2024-01-02"), day("2024-01-14"))
+	tt.Equal(ErrAnalysisWeekRange, err)
+
+	ranges, err = monthRanges(day("2024-01-01"), day("2024-02-29"))
+	tt.Equal(true, err == nil)
+	tt.Equal(2, len(ranges))
+	tt.Equal(day("2024-01-31"), ranges[0][1])
+	tt.Equal(day("2024-02-29"), ranges[1][1])
+	_, err = monthRanges(day("2024-01-01"), day("2024-02-28"))
+	tt.Equal(ErrAnalysisMonthRange, err)
+
+	weapp := &Weapp{AppID: "wx_analysis"}
+	New(weapp)
+	_, err = weapp.GetUserPortrait(day("2024-01-01"), 3)
+	tt.Equal(ErrAnalysisPortraitRange, err)
+}
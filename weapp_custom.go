@@ -0,0 +1,50 @@
+package wechat
+
+import (
+	"errors"
+	"io"
+)
+
+// EventUserEnterTempSession 用户进入小程序客服会话事件
+const EventUserEnterTempSession = "user_enter_tempsession"
+
+var ErrWeappCustomMessage = errors.New("weapp customer service only supports text, image, link and miniprogrampage messages")
+
+// SendCustomMessage 发送小程序客服消息，仅支持文本、图片、图文链接和小程序卡片
+func (m *Weapp) SendCustomMessage(openid string, msg CustomMessage) error {
+	switch msg.(type) {
+	case CustomText, CustomImage, CustomLink, CustomMiniProgramPage,
+		*CustomText, *CustomImage, *CustomLink, *CustomMiniProgramPage:
+	default:
+		return ErrWeappCustomMessage
+	}
+	_, err := m.post("/cgi-bin/message/custom/send", customMessageData(openid, msg, ""))
+	return err
+}
+
+// SetTyping 下发客服输入状态
+func (m *Weapp) SetTyping(openid string, typing bool) error {
+	e, err := m.checkEngine()
+	if err != nil {
+		return err
+	}
+	return e.SetTyping(openid, typing)
+}
+
+// UploadTempMedia 上传客服消息的临时图片素材
+func (m *Weapp) UploadTempMedia(fileName string, r io.Reader) (media MediaUpload, err error) {
+	var e *Engine
+	if e, err = m.checkEngine(); err != nil {
+		return
+	}
+	return e.UploadMedia(MediaTypeImage, fileName, r)
+}
+
+// GetTempMedia 获取客服消息的临时图片素材
+func (m *Weapp) GetTempMedia(mediaID string) ([]byte, error) {
+	e, err := m.checkEngine()
+	if err != nil {
+		return nil, err
+	}
+	return e.GetMedia(mediaID)
+}
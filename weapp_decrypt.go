@@ -0,0 +1,131 @@
+package wechat
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/sohaha/zlsgo/zstring"
+)
+
+type (
+	// WeappWatermark 开放数据水印
+	WeappWatermark struct {
+		AppID     string `json:"appid"`
+		Timestamp int64  `json:"timestamp"`
+	}
+
+	// WeappPhoneNumber 手机号
+	WeappPhoneNumber struct {
+		PhoneNumber     string         `json:"phoneNumber"`
+		PurePhoneNumber string         `json:"purePhoneNumber"`
+		CountryCode     string         `json:"countryCode"`
+		Watermark       WeappWatermark `json:"watermark"`
+	}
+
+	// WeappUserInfo 用户信息
+	WeappUserInfo struct {
+		OpenID    string         `json:"openId"`
+		NickName  string         `json:"nickName"`
+		Gender    int            `json:"gender"`
+		City      string         `json:"city"`
+		Province  string         `json:"province"`
+		Country   string         `json:"country"`
+		AvatarURL string         `json:"avatarUrl"`
+		UnionID   string         `json:"unionId"`
+		Language  string         `json:"language"`
+		Watermark WeappWatermark `json:"watermark"`
+	}
+
+	// WeappShareInfo 转发到群聊的分享信息
+	WeappShareInfo struct {
+		OpenGID   string         `json:"openGId"`
+		Watermark WeappWatermark `json:"watermark"`
+	}
+
+	// WeappRunData 微信运动步数
+	WeappRunData struct {
+		StepInfoList []struct {
+			Timestamp int64 `json:"timestamp"`
+			Step      int   `json:"step"`
+		} `json:"stepInfoList"`
+		Watermark WeappWatermark `json:"watermark"`
+	}
+
+	// WeappGroupInfo 从群聊进入小程序时的群信息
+	WeappGroupInfo struct {
+		OpenGID          string         `json:"opengid"`
+		OpenSingleRoomID string         `json:"open_single_roomid"`
+		ChatType         int            `json:"chat_type"`
+		Watermark        WeappWatermark `json:"watermark"`
+	}
+)
+
+var (
+	ErrDecryptData       = errors.New("invalid encrypted data")
+	ErrWatermarkAppID    = errors.New("watermark appid mismatch")
+	ErrWatermarkExpired  = errors.New("watermark expired")
+	errWatermarkNotFound = errors.New("watermark not found")
+)
+
+// checkWatermark 校验水印的 appid，并在设置了 WithWeappWatermarkMaxAge 时校验数据时效
+func (m *Weapp) checkWatermark(w WeappWatermark) error {
+	if w.AppID == "" {
+		return errWatermarkNotFound
+	}
+	if w.AppID != m.AppID {
+		return ErrWatermarkAppID
+	}
+	if m.engine != nil && m.engine.weappWatermarkMaxAge > 0 &&
+		time.Since(time.Unix(w.Timestamp, 0)) > m.engine.weappWatermarkMaxAge {
+		return ErrWatermarkExpired
+	}
+	return nil
+}
+
+func (m *Weapp) decryptData(sessionKey, iv, encryptedData string, v interface{}) error {
+	data, err := m.Decrypt(sessionKey, iv, encryptedData)
+	if err != nil {
+		return err
+	}
+	var watermark struct {
+		Watermark WeappWatermark `json:"watermark"`
+	}
+	if err = json.Unmarshal(zstring.String2Bytes(data), &watermark); err != nil {
+		return ErrDecryptData
+	}
+	if err = m.checkWatermark(watermark.Watermark); err != nil {
+		return err
+	}
+	return json.Unmarshal(zstring.String2Bytes(data), v)
+}
+
+// DecryptPhoneNumber 解密手机号
+func (m *Weapp) DecryptPhoneNumber(sessionKey, iv, encryptedData string) (info WeappPhoneNumber, err error) {
+	err = m.decryptData(sessionKey, iv, encryptedData, &info)
+	return
+}
+
+// DecryptUserInfo 解密用户信息
+func (m *Weapp) DecryptUserInfo(sessionKey, iv, encryptedData string) (info WeappUserInfo, err error) {
+	err = m.decryptData(sessionKey, iv, encryptedData, &info)
+	return
+}
+
+// DecryptShareInfo 解密群分享信息
+func (m *Weapp) DecryptShareInfo(sessionKey, iv, encryptedData string) (info WeappShareInfo, err error) {
+	err = m.decryptData(sessionKey, iv, encryptedData, &info)
+	return
+}
+
+// DecryptRunData 解密微信运动步数
+func (m *Weapp) DecryptRunData(sessionKey, iv, encryptedData string) (info WeappRunData, err error) {
+	err = m.decryptData(sessionKey, iv, encryptedData, &info)
+	return
+}
+
+// DecryptGroupInfo 解密群聊进入信息
+func (m *Weapp) DecryptGroupInfo(sessionKey, iv, encryptedData string) (info WeappGroupInfo, err error) {
+	err = m.decryptData(sessionKey, iv, encryptedData, &info)
+	return
+}
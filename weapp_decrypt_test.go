@@ -0,0 +1,75 @@
+package wechat
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func weappEncrypt(key, iv []byte, data string) string {
+	block, _ := aes.NewCipher(key)
+	plain := PKCS7Padding([]byte(data), block.BlockSize())
+	out := make([]byte, len(plain))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(out, plain)
+	return base64.StdEncoding.EncodeToString(out)
+}
+
+func TestWeappDecrypt(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_weapp_decrypt"}
+	New(weapp)
+
+	key, iv := []byte("0123456789abcdef"), []byte("fedcba9876543210")
+	sessionKey, ivStr := base64.StdEncoding.EncodeToString(key), base64.StdEncoding.EncodeToString(iv)
+
+	data := weappEncrypt(key, iv, `{"phoneNumber":"13800138000","purePhoneNumber":"13800138000","countryCode":"86","watermark":{"appid":"wx_weapp_decrypt","timestamp":1}}`)
+	phone, err := weapp.DecryptPhoneNumber(sessionKey, ivStr, data)
+	tt.Equal(true, err == nil)
+	tt.Equal("13800138000", phone.PurePhoneNumber)
+
+	weapp.engine.SetOptions(WithWeappWatermarkMaxAge(time.Hour))
+	_, err = weapp.DecryptPhoneNumber(sessionKey, ivStr, data)
+	tt.Equal(ErrWatermarkExpired, err)
+
+	data = weappEncrypt(key, iv, `{"openGId":"gid","watermark":{"appid":"wx_other","timestamp":1}}`)
+	_, err = weapp.DecryptShareInfo(sessionKey, ivStr, data)
+	tt.Equal(ErrWatermarkAppID, err)
+
+	_, err = weapp.Decrypt(base64.StdEncoding.EncodeToString([]byte("bad")), ivStr, data)
+	tt.Equal(true, err != nil)
+	_, err = weapp.Decrypt(sessionKey, ivStr, base64.StdEncoding.EncodeToString([]byte("short")))
+	tt.Equal(ErrDecryptData, err)
+	_, err = weapp.Decrypt(sessionKey, ivStr, "")
+	tt.Equal(ErrDecryptData, err)
+
+	tt.Equal(0, len(PKCS7UnPadding(nil, 16)))
+	_, err = aesDecrypt(data, "abcdefghijklmnopqrstuvwxyz0123456789ABCDEFG", "short")
+	tt.Equal(true, err != nil)
+}
+
+func TestComponentVerifyTicket(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	key := "abcdefghijklmnopqrstuvwxyz0123456789ABCDEFG"
+	wx := New(&Open{AppID: "wx_component", EncodingAesKey: key})
+
+	encrypt, err := aesEncrypt(MarshalPlainText(`<xml><ComponentVerifyTicket><![CDATA[ticket@@@1]]></ComponentVerifyTicket></xml>`, "wx_component", "1234567890123456"), key)
+	tt.Equal(true, err == nil)
+	ticket, err := wx.ComponentVerifyTicket(`<xml><Encrypt><![CDATA[` + string(encrypt) + `]]></Encrypt></xml>`)
+	tt.Equal(true, err == nil)
+	tt.Equal("ticket@@@1", ticket)
+
+	encrypt, _ = aesEncrypt(MarshalPlainText(`<xml></xml>`, "wx_other", "1234567890123456"), key)
+	_, err = wx.ComponentVerifyTicket(`<xml><Encrypt><![CDATA[` + string(encrypt) + `]]></Encrypt></xml>`)
+	tt.Equal(true, err != nil)
+
+	// 解密后长度不足时返回错误而不是越界
+	encrypt, _ = aesEncrypt("short", key)
+	_, err = wx.ComponentVerifyTicket(`<xml><Encrypt><![CDATA[` + string(encrypt) + `]]></Encrypt></xml>`)
+	tt.Equal(true, err != nil)
+	_, err = wx.ComponentVerifyTicket(`<xml><Encrypt><![CDATA[bad]]></Encrypt></xml>`)
+	tt.Equal(true, err != nil)
+}
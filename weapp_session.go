@@ -0,0 +1,184 @@
+package wechat
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/sohaha/zlsgo/zhttp"
+	"github.com/sohaha/zlsgo/zjson"
+	"github.com/sohaha/zlsgo/zstring"
+)
+
+type (
+	// WeappSession 小程序登录会话
+	WeappSession struct {
+		OpenID     string `json:"openid"`
+		UnionID    string `json:"unionid"`
+		SessionKey string `json:"session_key"`
+	}
+
+	// WeappLogin 小程序登录结果，Token 为下发给小程序的自定义登录态
+	WeappLogin struct {
+		Token string `json:"token"`
+		WeappSession
+	}
+)
+
+const (
+	weappSessionTokenLen   = 32
+	defaultWeappSessionTTL = 24 * time.Hour
+)
+
+var ErrWeappSession = errors.New("invalid or expired weapp session")
+
+func weappSessionSignature(sessionKey string) string {
+	h := hmac.New(sha256.New, zstring.String2Bytes(sessionKey))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (m *Weapp) saveSession(e *Engine, token string, session WeappSession) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	ttl := e.weappSessionTTL
+	if ttl <= 0 {
+		ttl = defaultWeappSessionTTL
+	}
+	e.cache.Set(cacheWeappSession+token, zstring.Bytes2String(data), uint(ttl/time.Second))
+	return nil
+}
+
+// Login 使用 wx.login 的 code 登录，保存 session_key 并生成自定义登录态
+func (m *Weapp) Login(code string) (login WeappLogin, err error) {
+	var e *Engine
+	if e, err = m.checkEngine(); err != nil {
+		return
+	}
+	var res *zjson.Res
+	res, err = m.GetSessionKey(code, "authorization_code")
+	if err != nil {
+		return
+	}
+	if err = unmarshalRes(res, &login.WeappSession); err != nil {
+		return
+	}
+	if login.OpenID == "" || login.SessionKey == "" {
+		return login, errors.New("session_key parsing failed")
+	}
+	login.Token = zstring.Rand(weappSessionTokenLen)
+	err = m.saveSession(e, login.Token, login.WeappSession)
+	return
+}
+
+// GetSession 通过自定义登录态获取会话
+func (m *Weapp) GetSession(token string) (session WeappSession, err error) {
+	var e *Engine
+	if e, err = m.checkEngine(); err != nil {
+		return
+	}
+	if len(token) != weappSessionTokenLen {
+		return session, ErrWeappSession
+	}
+	raw, err := e.cache.GetString(cacheWeappSession + token)
+	if err != nil || raw == "" {
+		return session, ErrWeappSession
+	}
+	if json.Unmarshal(zstring.String2Bytes(raw), &session) != nil {
+		return session, ErrWeappSession
+	}
+	return session, nil
+}
+
+// Logout 注销自定义登录态
+func (m *Weapp) Logout(token string) error {
+	e, err := m.checkEngine()
+	if err != nil {
+		return err
+	}
+	_, _ = e.cache.Delete(cacheWeappSession + token)
+	return nil
+}
+
+// CheckSessionKey 检验登录态 session_key 是否有效，失效时会同时注销登录态
+func (m *Weapp) CheckSessionKey(token string) error {
+	session, err := m.GetSession(token)
+	if err != nil {
+		return err
+	}
+	e := m.engine
+	_, err = e.HttpAccessTokenGet(e.apiURL+"/wxa/checksession", zhttp.QueryParam{
+		"openid":     session.OpenID,
+		"signature":  weappSessionSignature(session.SessionKey),
+		"sig_method": "hmac_sha256",
+	})
+	if ErrorCode(err) == 87009 {
+		_ = m.Logout(token)
+	}
+	return err
+}
+
+// ResetSessionKey 重置登录态 session_key，新的 session_key 会更新到会话中
+func (m *Weapp) ResetSessionKey(token string) (session WeappSession, err error) {
+	session, err = m.GetSession(token)
+	if err != nil {
+		return
+	}
+	e := m.engine
+	var res *zjson.Res
+	res, err = e.HttpAccessTokenGet(e.apiURL+"/wxa/resetusersessionkey", zhttp.QueryParam{
+		"openid":     session.OpenID,
+		"signature":  weappSessionSignature(session.SessionKey),
+		"sig_method": "hmac_sha256",
+	})
+	if err != nil {
+		return
+	}
+	sessionKey := res.Get("session_key").String()
+	if sessionKey == "" {
+		return session, errors.New("session_key parsing failed")
+	}
+	session.SessionKey = sessionKey
+	err = m.saveSession(e, token, session)
+	return
+}
+
+// DecryptSession 使用登录态对应的 session_key 解密开放数据
+func (m *Weapp) DecryptSession(token, iv, encryptedData string) (string, error) {
+	session, err := m.GetSession(token)
+	if err != nil {
+		return "", err
+	}
+	return m.Decrypt(session.SessionKey, iv, encryptedData)
+}
+
+// VerifySession 使用登录态对应的 session_key 校验数据签名
+func (m *Weapp) VerifySession(token, rawData, signature string) bool {
+	session, err := m.GetSession(token)
+	if err != nil {
+		return false
+	}
+	return m.Verify(session.SessionKey, rawData, signature)
+}
+
+// DecryptSessionPhoneNumber 使用登录态对应的 session_key 解密手机号
+func (m *Weapp) DecryptSessionPhoneNumber(token, iv, encryptedData string) (info WeappPhoneNumber, err error) {
+	var session WeappSession
+	if session, err = m.GetSession(token); err != nil {
+		return
+	}
+	return m.DecryptPhoneNumber(session.SessionKey, iv, encryptedData)
+}
+
+// DecryptSessionUserInfo 使用登录态对应的 session_key 解密用户信息
+func (m *Weapp) DecryptSessionUserInfo(token, iv, encryptedData string) (info WeappUserInfo, err error) {
+	var session WeappSession
+	if session, err = m.GetSession(token); err != nil {
+		return
+	}
+	return m.DecryptUserInfo(session.SessionKey, iv, encryptedData)
+}
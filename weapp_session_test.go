@@ -0,0 +1,67 @@
+package wechat
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+	"github.com/sohaha/zlsgo/zstring"
+)
+
+func TestWeappSession(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_weapp_session"}
+	New(weapp)
+
+	token := zstring.Rand(weappSessionTokenLen)
+	err := weapp.saveSession(weapp.engine, token, WeappSession{OpenID: "openid", SessionKey: "key"})
+	tt.Equal(true, err == nil)
+
+	session, err := weapp.GetSession(token)
+	tt.Equal(true, err == nil)
+	tt.Equal("openid", session.OpenID)
+	tt.Equal("key", session.SessionKey)
+
+	// 会话随引擎缓存持久化，重启后仍有效
+	dir, _ := ioutil.TempDir("", "wechat")
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cache.json")
+	data, err := SaveCacheData(path)
+	tt.Equal(true, err == nil)
+	tt.Equal(true, strings.Contains(data, cacheWeappSession+token))
+	weapp.engine.cache.Clear()
+	_, err = weapp.GetSession(token)
+	tt.Equal(ErrWeappSession, err)
+	tt.Equal(true, LoadCacheData(path) == nil)
+	session, err = weapp.GetSession(token)
+	tt.Equal(true, err == nil)
+	tt.Equal("key", session.SessionKey)
+
+	tt.Equal(true, weapp.Logout(token) == nil)
+	_, err = weapp.GetSession(token)
+	tt.Equal(ErrWeappSession, err)
+
+	_, err = weapp.DecryptSession("forged", "", "")
+	tt.Equal(ErrWeappSession, err)
+	_, err = weapp.DecryptSessionPhoneNumber("forged", "", "")
+	tt.Equal(ErrWeappSession, err)
+
+	key, iv := []byte("0123456789abcdef"), []byte("fedcba9876543210")
+	token = zstring.Rand(weappSessionTokenLen)
+	_ = weapp.saveSession(weapp.engine, token, WeappSession{OpenID: "openid", SessionKey: base64.StdEncoding.EncodeToString(key)})
+	ivStr := base64.StdEncoding.EncodeToString(iv)
+	phone, err := weapp.DecryptSessionPhoneNumber(token, ivStr, weappEncrypt(key, iv,
+		`{"phoneNumber":"13800138000","purePhoneNumber":"13800138000","countryCode":"86","watermark":{"appid":"wx_weapp_session","timestamp":1}}`))
+	tt.Equal(true, err == nil)
+	tt.Equal("13800138000", phone.PurePhoneNumber)
+	info, err := weapp.DecryptSessionUserInfo(token, ivStr, weappEncrypt(key, iv,
+		`{"openId":"openid","nickName":"nick","watermark":{"appid":"wx_weapp_session","timestamp":1}}`))
+	tt.Equal(true, err == nil)
+	tt.Equal("nick", info.NickName)
+
+	tt.Equal("b613679a0814d9ec772f95d778c35fc5ff1697c493715653c6c712144292c5ad", weappSessionSignature(""))
+}
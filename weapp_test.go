@@ -0,0 +1,38 @@
+package wechat
+
+import (
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestGetPhoneNumber(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_phone"}
+	e := New(weapp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		switch r.JSON("code").String() {
+		case "expired":
+			return `{"errcode":40029,"errmsg":"invalid code"}`
+		case "other":
+			return `{"errcode":0,"errmsg":"ok","phone_info":{"phoneNumber":"13800138000","purePhoneNumber":"13800138000","countryCode":"86","watermark":{"appid":"wx_other","timestamp":1}}}`
+		}
+		return `{"errcode":0,"errmsg":"ok","phone_info":{"phoneNumber":"+86 13800138000","purePhoneNumber":"13800138000","countryCode":"86","watermark":{"appid":"wx_phone","timestamp":1}}}`
+	})
+	defer api.Close()
+
+	info, err := weapp.GetPhoneNumber("code")
+	tt.Equal(true, err == nil)
+	tt.Equal("13800138000", info.PurePhoneNumber)
+	tt.Equal("86", info.CountryCode)
+	req := api.Last()
+	tt.Equal("/wxa/business/getuserphonenumber", req.Path)
+	tt.Equal("ACCESS_TOKEN", req.Query.Get("access_token"))
+	tt.Equal("code", req.JSON("code").String())
+
+	_, err = weapp.GetPhoneNumber("expired")
+	tt.Equal(40029, ErrorCode(err))
+
+	_, err = weapp.GetPhoneNumber("other")
+	tt.Equal(ErrWatermarkAppID, err)
+}
@@ -2,6 +2,7 @@ package wechat
 
 import (
 	"errors"
+	"net/url"
 	"strings"
 
 	"github.com/sohaha/zlsgo/znet"
@@ -11,30 +12,59 @@ import (
 type RouterOption struct {
 	Prefix              string
 	JsapiTicketCallback func(*znet.Context, ztype.Map, error)
+	// JsDomains 允许签名的页面域名，为空时仅允许当前域名
+	JsDomains   []string
+	JsAPIList   []string
+	OpenTagList []string
+	JsDebug     bool
 }
 
 func (w *Engine) Router(r *znet.Engine, opt RouterOption) {
 	opt.Prefix = strings.TrimRight(opt.Prefix, "/")
 
 	r.GET(opt.Prefix+"/js_ticket", func(c *znet.Context) {
-		opt.JsapiTicketCallback(getJsapiTicket(w, c))
+		opt.JsapiTicketCallback(getJsapiTicket(w, c, opt))
 	})
 }
 
-func getJsapiTicket(wx *Engine, c *znet.Context) (*znet.Context, ztype.Map, error) {
+func getJsapiTicket(wx *Engine, c *znet.Context, opt RouterOption) (*znet.Context, ztype.Map, error) {
+	pageURL, _ := c.GetQuery("url")
+	if pageURL == "" {
+		return c, nil, errors.New("missing url parameter")
+	}
+	domains := opt.JsDomains
+	if len(domains) == 0 {
+		host := c.Host(true)
+		if len(wx.redirectDomain) > 0 {
+			host = wx.redirectDomain
+		}
+		if u, err := url.Parse(host); err == nil {
+			domains = []string{u.Hostname()}
+		}
+	}
+	pageURL, err := jsPageURL(pageURL, domains)
+	if err != nil {
+		return c, nil, err
+	}
 	jsapiTicket, err := wx.GetJsapiTicket()
 	if err != nil {
 		return c, nil, errors.New(ErrorMsg(err))
 	}
-	url := c.Host(true)
-	jsSign, err := wx.GetJsSign(url)
+	config, err := wx.GetJSConfig(pageURL, WithJSAPIList(opt.JsAPIList...),
+		WithOpenTagList(opt.OpenTagList...), WithJSDebug(opt.JsDebug))
 	if err != nil {
 		return c, nil, errors.New(ErrorMsg(err))
 	}
 
 	return c, map[string]interface{}{
 		"jsapiTicket": jsapiTicket,
-		"jsSign":      jsSign,
-		"url":         url,
+		"jsSign": JsSign{
+			AppID:     config.AppID,
+			Timestamp: config.Timestamp,
+			NonceStr:  config.NonceStr,
+			Signature: config.Signature,
+		},
+		"config": config,
+		"url":    pageURL,
 	}, nil
 }
@@ -0,0 +1,58 @@
+package wechat
+
+import (
+	"errors"
+
+	"github.com/sohaha/zlsgo/zhttp"
+)
+
+type (
+	// Website 开放平台网站应用，用于网站扫码登录
+	Website struct {
+		AppID     string
+		AppSecret string
+		engine    *Engine
+	}
+)
+
+var _ Cfg = new(Website)
+
+func (w *Website) setEngine(engine *Engine) {
+	w.engine = engine
+}
+
+func (w *Website) getEngine() *Engine {
+	return w.engine
+}
+
+func (w *Website) checkEngine() (*Engine, error) {
+	if w.engine == nil {
+		return nil, errors.New(`please use wechat.New(&wechat.Website{})`)
+	}
+	return w.engine, nil
+}
+
+func (w *Website) GetAppID() string {
+	return w.AppID
+}
+
+func (w *Website) GetSecret() string {
+	return w.AppSecret
+}
+
+func (w *Website) GetToken() string {
+	return ""
+}
+
+func (w *Website) GetEncodingAesKey() string {
+	return ""
+}
+
+// getAccessToken 网站应用没有接口调用凭证
+func (w *Website) getAccessToken() (data []byte, err error) {
+	return nil, errors.New("not support")
+}
+
+func (w *Website) getJsapiTicket() (data *zhttp.Res, err error) {
+	return nil, errors.New("not support")
+}
@@ -29,11 +29,15 @@ type (
 	}
 
 	Engine struct {
-		config         Cfg
-		cache          *zcache.Table
-		action         string
-		apiURL         string
-		redirectDomain string
+		config               Cfg
+		cache                *zcache.Table
+		action               string
+		apiURL               string
+		redirectDomain       string
+		authRedirectHosts    []string
+		authStateTTL         time.Duration
+		weappSessionTTL      time.Duration
+		weappWatermarkMaxAge time.Duration
 	}
 )
 
@@ -44,6 +48,14 @@ const (
 	cachePrtfix                = "go_wechat_"
 	cacheToken                 = "Token"
 	cacheJsapiTicket           = "JsapiTicket"
+	cacheAgentJsapiTicket      = "AgentJsapiTicket"
+	cacheCardTicket            = "CardTicket"
+	cacheAuthState             = "AuthState"
+	cacheWeappSession          = "WeappSession"
+	cacheMediaCheck            = "MediaCheck"
+	cacheSubscribeQuota        = "SubscribeQuota"
+	cacheSubscribeTemplates    = "SubscribeTemplates"
+	cacheSubscribePending      = "SubscribePending"
 	cacheComponentVerifyTicket = "componentVerifyTicket"
 )
 
@@ -185,6 +197,8 @@ func New(c Cfg) *Engine {
 	switch c.(type) {
 	case *Open:
 		action = "open"
+	case *Website:
+		action = "website"
 	case *Qy:
 		action = "qy"
 		apiURL = QyAPIURL
@@ -234,6 +248,11 @@ func (e *Engine) IsOpen() bool {
 	return e.action == "open"
 }
 
+// IsWebsite 是否网站应用
+func (e *Engine) IsWebsite() bool {
+	return e.action == "website"
+}
+
 // IsWeapp 是否小程序
 func (e *Engine) IsWeapp() bool {
 	return e.action == "weapp"
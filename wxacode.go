@@ -0,0 +1,199 @@
+package wechat
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+type (
+	// WxaCodeColor 小程序码线条颜色
+	WxaCodeColor struct {
+		R int `json:"r"`
+		G int `json:"g"`
+		B int `json:"b"`
+	}
+
+	// WxaCodeOptions 小程序码选项
+	WxaCodeOptions struct {
+		Width     int
+		AutoColor bool
+		LineColor *WxaCodeColor
+		IsHyaline bool
+		// EnvVersion 要打开的小程序版本，默认为正式版
+		EnvVersion string
+		// NoCheckPath 不校验 page 是否存在，仅 GetWxaCodeUnlimit 有效
+		NoCheckPath bool
+	}
+
+	WxaCodeOption func(*WxaCodeOptions)
+)
+
+const (
+	WeappEnvRelease = "release"
+	WeappEnvTrial   = "trial"
+	WeappEnvDevelop = "develop"
+
+	wxaCodeMaxSceneLen = 32
+	wxaCodeMaxPathLen  = 1024
+	wxaCodeSceneChars  = "!#$&'()*+,/:;=?@-._~"
+)
+
+var (
+	ErrWxaCodeScene = errors.New("wxacode scene must be 1-32 characters of letters, digits or !#$&'()*+,/:;=?@-._~")
+	ErrWxaCodePath  = errors.New("the length of wxacode path must be between 1 and 1024")
+)
+
+// WithWxaCodeWidth 二维码宽度，单位 px，最小 280，最大 1280
+func WithWxaCodeWidth(width int) WxaCodeOption {
+	return func(o *WxaCodeOptions) {
+		o.Width = width
+	}
+}
+
+// WithWxaCodeAutoColor 自动配置线条颜色
+func WithWxaCodeAutoColor() WxaCodeOption {
+	return func(o *WxaCodeOptions) {
+		o.AutoColor = true
+	}
+}
+
+// WithWxaCodeLineColor 线条颜色
+func WithWxaCodeLineColor(r, g, b int) WxaCodeOption {
+	return func(o *WxaCodeOptions) {
+		o.AutoColor = false
+		o.LineColor = &WxaCodeColor{R: r, G: g, B: b}
+	}
+}
+
+// WithWxaCodeHyaline 透明底色
+func WithWxaCodeHyaline() WxaCodeOption {
+	return func(o *WxaCodeOptions) {
+		o.IsHyaline = true
+	}
+}
+
+// WithWxaCodeEnvVersion 要打开的小程序版本
+func WithWxaCodeEnvVersion(envVersion string) WxaCodeOption {
+	return func(o *WxaCodeOptions) {
+		o.EnvVersion = envVersion
+	}
+}
+
+// WithWxaCodeNoCheckPath 不校验 page 是否存在，可用于生成未发布页面的小程序码
+func WithWxaCodeNoCheckPath() WxaCodeOption {
+	return func(o *WxaCodeOptions) {
+		o.NoCheckPath = true
+	}
+}
+
+func checkWxaCodeScene(scene string) error {
+	if scene == "" || len(scene) > wxaCodeMaxSceneLen {
+		return ErrWxaCodeScene
+	}
+	for _, c := range scene {
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+			strings.ContainsRune(wxaCodeSceneChars, c) {
+			continue
+		}
+		return ErrWxaCodeScene
+	}
+	return nil
+}
+
+func wxaCodeData(data map[string]interface{}, opts []WxaCodeOption) (map[string]interface{}, WxaCodeOptions) {
+	o := WxaCodeOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Width > 0 {
+		data["width"] = o.Width
+	}
+	if o.AutoColor {
+		data["auto_color"] = true
+	}
+	if o.LineColor != nil {
+		data["line_color"] = o.LineColor
+	}
+	if o.IsHyaline {
+		data["is_hyaline"] = true
+	}
+	if o.EnvVersion != "" {
+		data["env_version"] = o.EnvVersion
+	}
+	return data, o
+}
+
+func (m *Weapp) wxaCode(path string, data map[string]interface{}) ([]byte, error) {
+	e, err := m.checkEngine()
+	if err != nil {
+		return nil, err
+	}
+	return e.HttpAccessTokenPostRaw(e.apiURL+path, data)
+}
+
+// GetWxaCode 获取小程序码，适用于需要的码数量较少的业务场景，与 CreateWxaQrCode 合计限 10 万个
+func (m *Weapp) GetWxaCode(path string, opts ...WxaCodeOption) ([]byte, error) {
+	if path == "" || len(path) > wxaCodeMaxPathLen {
+		return nil, ErrWxaCodePath
+	}
+	data, _ := wxaCodeData(map[string]interface{}{"path": path}, opts)
+	return m.wxaCode("/wxa/getwxacode", data)
+}
+
+// GetWxaCodeUnlimit 获取不限制数量的小程序码，page 为空时默认进入主页
+func (m *Weapp) GetWxaCodeUnlimit(scene, page string, opts ...WxaCodeOption) ([]byte, error) {
+	if err := checkWxaCodeScene(scene); err != nil {
+		return nil, err
+	}
+	data, o := wxaCodeData(map[string]interface{}{"scene": scene}, opts)
+	if page != "" {
+		data["page"] = page
+	}
+	if o.NoCheckPath {
+		data["check_path"] = false
+	}
+	return m.wxaCode("/wxa/getwxacodeunlimit", data)
+}
+
+// CreateWxaQrCode 获取小程序二维码，与 GetWxaCode 合计限 10 万个
+func (m *Weapp) CreateWxaQrCode(path string, width int) ([]byte, error) {
+	if path == "" || len(path) > wxaCodeMaxPathLen {
+		return nil, ErrWxaCodePath
+	}
+	data := map[string]interface{}{"path": path}
+	if width > 0 {
+		data["width"] = width
+	}
+	return m.wxaCode("/cgi-bin/wxaapp/createwxaqrcode", data)
+}
+
+// WriteWxaCode 获取小程序码并写入 w
+func (m *Weapp) WriteWxaCode(path string, w io.Writer, opts ...WxaCodeOption) error {
+	b, err := m.GetWxaCode(path, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// WriteWxaCodeUnlimit 获取不限制数量的小程序码并写入 w
+func (m *Weapp) WriteWxaCodeUnlimit(scene, page string, w io.Writer, opts ...WxaCodeOption) error {
+	b, err := m.GetWxaCodeUnlimit(scene, page, opts...)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// WriteWxaQrCode 获取小程序二维码并写入 w
+func (m *Weapp) WriteWxaQrCode(path string, width int, w io.Writer) error {
+	b, err := m.CreateWxaQrCode(path, width)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
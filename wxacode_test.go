@@ -0,0 +1,99 @@
+package wechat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestWxaCodeScene(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	for scene, ok := range map[string]bool{
+		"id=1&from=share":         true,
+		"a-b_c.d~e!#$'()*+,/:;?@": true,
+		"":                        false,
+		strings.Repeat("a", 33):   false,
+		"中文":                      false,
+		"has space":               false,
+		"percent%20":              false,
+		strings.Repeat("a", 32):   true,
+	} {
+		tt.Equal(ok, checkWxaCodeScene(scene) == nil)
+	}
+
+	data, o := wxaCodeData(map[string]interface{}{}, []WxaCodeOption{
+		WithWxaCodeWidth(430), WithWxaCodeLineColor(0, 0, 0), WithWxaCodeNoCheckPath(),
+	})
+	tt.Equal(430, data["width"])
+	tt.Equal(true, o.NoCheckPath)
+	tt.Equal(&WxaCodeColor{}, data["line_color"])
+}
+
+func TestWxaCodeRequest(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_wxacode"}
+	e := New(weapp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		if r.JSON("path").String() == "pages/error" {
+			return `{"errcode":41030,"errmsg":"invalid page"}`
+		}
+		return "\x89PNG\r\n"
+	})
+	defer api.Close()
+
+	b, err := weapp.GetWxaCodeUnlimit("id=1", "pages/index", WithWxaCodeNoCheckPath(), WithWxaCodeEnvVersion("trial"))
+	tt.Equal(true, err == nil)
+	tt.Equal("\x89PNG\r\n", string(b))
+	req := api.Last()
+	tt.Equal("/wxa/getwxacodeunlimit", req.Path)
+	tt.Equal("id=1", req.JSON("scene").String())
+	tt.Equal("pages/index", req.JSON("page").String())
+	tt.Equal("trial", req.JSON("env_version").String())
+	tt.Equal(true, req.JSON("check_path").Exists())
+	tt.Equal(false, req.JSON("check_path").Bool())
+
+	// check_path 仅 getwxacodeunlimit 支持
+	_, err = weapp.GetWxaCode("pages/index", WithWxaCodeNoCheckPath())
+	tt.Equal(true, err == nil)
+	req = api.Last()
+	tt.Equal("/wxa/getwxacode", req.Path)
+	tt.Equal(false, req.JSON("check_path").Exists())
+
+	_, err = weapp.GetWxaCode("pages/error")
+	tt.Equal(41030, ErrorCode(err))
+}
+
+func TestWxaCodeTokenExpired(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_wxacode_expired", AppSecret: "secret"}
+	e := New(weapp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		switch {
+		case r.Path == "/cgi-bin/token":
+			return `{"access_token":"NEW_TOKEN","expires_in":7200}`
+		case r.Query.Get("access_token") == "ACCESS_TOKEN":
+			// 出错时返回 JSON 而非图片
+			return `{"errcode":42001,"errmsg":"access_token expired"}`
+		}
+		return "\x89PNG\r\n"
+	})
+	defer api.Close()
+
+	b, err := weapp.GetWxaCodeUnlimit("id=1", "pages/index")
+	tt.Equal(true, err == nil)
+	tt.Equal("\x89PNG\r\n", string(b))
+	reqs := api.Requests()
+	tt.Equal(3, len(reqs))
+	tt.Equal("/cgi-bin/token", reqs[1].Path)
+	tt.Equal("/wxa/getwxacodeunlimit", reqs[2].Path)
+	tt.Equal("NEW_TOKEN", reqs[2].Query.Get("access_token"))
+	tt.Equal("id=1", reqs[2].JSON("scene").String())
+}
+
+func TestIsJSONBody(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	tt.Equal(true, isJSONBody([]byte(` {"errcode":40001,"errmsg":"invalid credential"}`)))
+	tt.Equal(false, isJSONBody([]byte("\x89PNG\r\n")))
+	tt.Equal(false, isJSONBody([]byte("{not json")))
+}
@@ -0,0 +1,213 @@
+package wechat
+
+import (
+	"time"
+
+	"github.com/sohaha/zlsgo/zjson"
+)
+
+type (
+	// WxaLinkCloudBase 云开发静态网站 H5 跳转配置，仅 URL Link 有效
+	WxaLinkCloudBase struct {
+		Env           string `json:"env"`
+		Domain        string `json:"domain,omitempty"`
+		Path          string `json:"path,omitempty"`
+		Query         string `json:"query,omitempty"`
+		ResourceAppID string `json:"resource_appid,omitempty"`
+	}
+
+	// WxaLinkOptions Scheme 与 URL Link 选项
+	WxaLinkOptions struct {
+		EnvVersion string
+		// ExpireTime 到期失效时间
+		ExpireTime time.Time
+		// ExpireInterval 失效间隔天数
+		ExpireInterval int
+		CloudBase      *WxaLinkCloudBase
+	}
+
+	WxaLinkOption func(*WxaLinkOptions)
+
+	// WxaLinkInfo Scheme 或 URL Link 信息
+	WxaLinkInfo struct {
+		AppID      string            `json:"appid"`
+		Path       string            `json:"path"`
+		Query      string            `json:"query"`
+		CreateTime int64             `json:"create_time"`
+		ExpireTime int64             `json:"expire_time"`
+		EnvVersion string            `json:"env_version"`
+		CloudBase  *WxaLinkCloudBase `json:"cloud_base,omitempty"`
+	}
+
+	// WxaLinkQuota 长期有效链接的配额
+	WxaLinkQuota struct {
+		LongTimeUsed  int `json:"long_time_used"`
+		LongTimeLimit int `json:"long_time_limit"`
+	}
+
+	// WxaSchemeQuery Scheme 查询结果
+	WxaSchemeQuery struct {
+		SchemeInfo  WxaLinkInfo  `json:"scheme_info"`
+		SchemeQuota WxaLinkQuota `json:"scheme_quota"`
+	}
+
+	// WxaURLLinkQuery URL Link 查询结果
+	WxaURLLinkQuery struct {
+		URLLinkInfo  WxaLinkInfo  `json:"url_link_info"`
+		URLLinkQuota WxaLinkQuota `json:"url_link_quota"`
+		VisitOpenID  string       `json:"visit_openid"`
+	}
+)
+
+const (
+	wxaLinkExpireTime     = 0
+	wxaLinkExpireInterval = 1
+	wxaLinkMaxExpire      = 30
+)
+
+// WithWxaLinkEnvVersion 要打开的小程序版本
+func WithWxaLinkEnvVersion(envVersion string) WxaLinkOption {
+	return func(o *WxaLinkOptions) {
+		o.EnvVersion = envVersion
+	}
+}
+
+// WithWxaLinkExpireTime 到期失效时间，最长为 30 天
+func WithWxaLinkExpireTime(t time.Time) WxaLinkOption {
+	return func(o *WxaLinkOptions) {
+		o.ExpireTime = t
+		o.ExpireInterval = 0
+	}
+}
+
+// WithWxaLinkExpireInterval 生成后 days 天失效，最长为 30 天
+func WithWxaLinkExpireInterval(days int) WxaLinkOption {
+	return func(o *WxaLinkOptions) {
+		o.ExpireInterval = days
+		o.ExpireTime = time.Time{}
+	}
+}
+
+// WithWxaLinkCloudBase 云开发静态网站 H5 跳转配置
+func WithWxaLinkCloudBase(cloudBase WxaLinkCloudBase) WxaLinkOption {
+	return func(o *WxaLinkOptions) {
+		o.CloudBase = &cloudBase
+	}
+}
+
+func wxaLinkOptions(opts []WxaLinkOption) (o WxaLinkOptions, err error) {
+	for _, opt := range opts {
+		opt(&o)
+	}
+	switch o.EnvVersion {
+	case "", WeappEnvRelease, WeappEnvTrial, WeappEnvDevelop:
+	default:
+		return o, codeError(85402)
+	}
+	if !o.ExpireTime.IsZero() {
+		now := time.Now()
+		if !o.ExpireTime.After(now) || o.ExpireTime.After(now.AddDate(0, 0, wxaLinkMaxExpire)) {
+			return o, codeError(85401)
+		}
+	}
+	if o.ExpireInterval < 0 || o.ExpireInterval > wxaLinkMaxExpire {
+		return o, codeError(85401)
+	}
+	return
+}
+
+func (o WxaLinkOptions) expire(data map[string]interface{}) {
+	if !o.ExpireTime.IsZero() {
+		data["is_expire"] = true
+		data["expire_type"] = wxaLinkExpireTime
+		data["expire_time"] = o.ExpireTime.Unix()
+	} else if o.ExpireInterval > 0 {
+		data["is_expire"] = true
+		data["expire_type"] = wxaLinkExpireInterval
+		data["expire_interval"] = o.ExpireInterval
+	}
+}
+
+// IsWxaLinkQuotaError 是否为 Scheme、URL Link 生成数量或频率超限的错误
+func IsWxaLinkQuotaError(err error) bool {
+	switch ErrorCode(err) {
+	case 85400, 45009, 44990:
+		return true
+	}
+	return false
+}
+
+// GenerateScheme 生成小程序 Scheme 码，path 为空时进入主页
+func (m *Weapp) GenerateScheme(path, query string, opts ...WxaLinkOption) (string, error) {
+	o, err := wxaLinkOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	jump := map[string]interface{}{"path": path, "query": query}
+	if o.EnvVersion != "" {
+		jump["env_version"] = o.EnvVersion
+	}
+	data := map[string]interface{}{"jump_wxa": jump}
+	o.expire(data)
+	res, err := m.post("/wxa/generatescheme", data)
+	if err != nil {
+		return "", err
+	}
+	return res.Get("openlink").String(), nil
+}
+
+// QueryScheme 查询 Scheme 码
+func (m *Weapp) QueryScheme(scheme string) (query WxaSchemeQuery, err error) {
+	var res *zjson.Res
+	res, err = m.post("/wxa/queryscheme", map[string]string{"scheme": scheme})
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &query)
+	return
+}
+
+// GenerateURLLink 生成小程序 URL Link，path 为空时进入主页
+func (m *Weapp) GenerateURLLink(path, query string, opts ...WxaLinkOption) (string, error) {
+	o, err := wxaLinkOptions(opts)
+	if err != nil {
+		return "", err
+	}
+	data := map[string]interface{}{"path": path, "query": query}
+	if o.EnvVersion != "" {
+		data["env_version"] = o.EnvVersion
+	}
+	if o.CloudBase != nil {
+		data["cloud_base"] = o.CloudBase
+	}
+	o.expire(data)
+	res, err := m.post("/wxa/generate_urllink", data)
+	if err != nil {
+		return "", err
+	}
+	return res.Get("url_link").String(), nil
+}
+
+// QueryURLLink 查询 URL Link
+func (m *Weapp) QueryURLLink(urlLink string) (query WxaURLLinkQuery, err error) {
+	var res *zjson.Res
+	res, err = m.post("/wxa/query_urllink", map[string]string{"url_link": urlLink})
+	if err != nil {
+		return
+	}
+	err = unmarshalRes(res, &query)
+	return
+}
+
+// GenerateShortLink 生成小程序 Short Link，permanent 为 true 时生成永久有效的链接，Short Link 无查询接口
+func (m *Weapp) GenerateShortLink(pageURL, pageTitle string, permanent bool) (string, error) {
+	res, err := m.post("/wxa/genwxashortlink", map[string]interface{}{
+		"page_url":     pageURL,
+		"page_title":   pageTitle,
+		"is_permanent": permanent,
+	})
+	if err != nil {
+		return "", err
+	}
+	return res.Get("link").String(), nil
+}
@@ -0,0 +1,68 @@
+package wechat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sohaha/zlsgo"
+)
+
+func TestWxaLinkOptions(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+
+	o, err := wxaLinkOptions([]WxaLinkOption{WithWxaLinkExpireInterval(7), WithWxaLinkEnvVersion(WeappEnvTrial)})
+	tt.Equal(true, err == nil)
+	data := map[string]interface{}{}
+	o.expire(data)
+	tt.Equal(wxaLinkExpireInterval, data["expire_type"])
+	tt.Equal(7, data["expire_interval"])
+
+	_, err = wxaLinkOptions([]WxaLinkOption{WithWxaLinkExpireInterval(31)})
+	tt.Equal(85401, ErrorCode(err))
+	_, err = wxaLinkOptions([]WxaLinkOption{WithWxaLinkExpireTime(time.Now().Add(-time.Minute))})
+	tt.Equal(85401, ErrorCode(err))
+	_, err = wxaLinkOptions([]WxaLinkOption{WithWxaLinkEnvVersion("beta")})
+	tt.Equal(85402, ErrorCode(err))
+
+	tt.Equal(true, IsWxaLinkQuotaError(codeError(85400)))
+	tt.Equal(false, IsWxaLinkQuotaError(codeError(40165)))
+}
+
+func TestWxaLinkRequest(t *testing.T) {
+	tt := zlsgo.NewTest(t)
+	weapp := &Weapp{AppID: "wx_wxalink"}
+	e := New(weapp)
+	api := newMockAPI(e, func(r mockRequest) string {
+		switch r.Path {
+		case "/wxa/generatescheme":
+			return `{"errcode":0,"errmsg":"ok","openlink":"weixin://dl/business/?t=XTSkBZlzqmn"}`
+		case "/wxa/generate_urllink":
+			return `{"errcode":85400,"errmsg":"long time expire url link over limit"}`
+		case "/wxa/genwxashortlink":
+			return `{"errcode":0,"errmsg":"ok","link":"#小程序://小程序名称/示例/6lO1Xk6OYxm31Fz"}`
+		}
+		return ""
+	})
+	defer api.Close()
+
+	link, err := weapp.GenerateScheme("pages/index", "a=1", WithWxaLinkEnvVersion(WeappEnvTrial), WithWxaLinkExpireInterval(7))
+	tt.Equal(true, err == nil)
+	tt.Equal("weixin://dl/business/?t=XTSkBZlzqmn", link)
+	req := api.Last()
+	tt.Equal("pages/index", req.JSON("jump_wxa.path").String())
+	tt.Equal(WeappEnvTrial, req.JSON("jump_wxa.env_version").String())
+	tt.Equal(7, req.JSON("expire_interval").Int())
+
+	_, err = weapp.GenerateURLLink("pages/index", "", WithWxaLinkCloudBase(WxaLinkCloudBase{Env: "env"}))
+	tt.Equal(true, IsWxaLinkQuotaError(err))
+	tt.Equal("env", api.Last().JSON("cloud_base.env").String())
+
+	link, err = weapp.GenerateShortLink("pages/index?a=1", "title", true)
+	tt.Equal(true, err == nil)
+	tt.Equal("#小程序://小程序名称/示例/6lO1Xk6OYxm31Fz", link)
+	tt.Equal(true, api.Last().JSON("is_permanent").Bool())
+
+	_, err = weapp.GenerateScheme("", "", WithWxaLinkEnvVersion("beta"))
+	tt.Equal(85402, ErrorCode(err))
+	tt.Equal(3, len(api.Requests()))
+}